@@ -2,28 +2,87 @@ package unfmt
 
 import (
 	"fmt"
+	"image/color"
+	"math"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 type runes string
 
 const (
-	boolRunes runes = "01truefalseTRUEFALSE"
-	intRunes  runes = "+-0123456789"
+	boolRunes  runes = "01truefalseTRUEFALSE"
+	intRunes   runes = "+-0123456789"
+	digitRunes runes = "0123456789"
+	hexRunes   runes = "0123456789abcdefABCDEF"
 )
 
-type assignFunc func(string, interface{}) (int, error)
+type assignFunc func(string, interface{}, verb) (int, error)
 
-var assignFuncs = map[rune]assignFunc{
-	verbBool:   assignBool,
-	verbString: assignString,
-	verbInt:    assignInt,
+var assignFuncs = map[string]assignFunc{
+	verbBool:     assignBool,
+	verbString:   assignString,
+	verbInt:      assignInt,
+	verbEpoch:    assignEpoch,
+	verbDuration: assignDuration,
+	verbIP:       assignIP,
+	verbPrefix:   assignPrefix,
+	verbAddrPort: assignAddrPort,
+	verbMAC:      assignMAC,
+	verbURL:      assignURL,
+	verbUUID:     assignUUID,
+	verbVersion:  assignVersion,
+	verbQuantity: assignQuantity,
+	verbDecimal:  assignDecimal,
+	verbColor:    assignColor,
+	verbEnum:     assignEnum,
+	verbTime:     assignTime,
 }
 
-func isSupportedVerb(r rune) bool {
-	_, ok := assignFuncs[r]
-	return ok
+// Allocate a value of the type each verb most naturally assigns to.
+var newTargetFuncs = map[string]func() interface{}{
+	verbBool:     func() interface{} { return new(bool) },
+	verbString:   func() interface{} { return new(string) },
+	verbInt:      func() interface{} { return new(int64) },
+	verbEpoch:    func() interface{} { return new(time.Time) },
+	verbDuration: func() interface{} { return new(time.Duration) },
+	verbIP:       func() interface{} { return new(netip.Addr) },
+	verbPrefix:   func() interface{} { return new(netip.Prefix) },
+	verbAddrPort: func() interface{} { return new(netip.AddrPort) },
+	verbMAC:      func() interface{} { return new(net.HardwareAddr) },
+	verbURL:      func() interface{} { return new(url.URL) },
+	verbUUID:     func() interface{} { return new([16]byte) },
+	verbVersion:  func() interface{} { return new(Version) },
+	verbQuantity: func() interface{} { return new(float64) },
+	verbDecimal:  func() interface{} { return new(big.Rat) },
+	verbColor:    func() interface{} { return new(color.NRGBA) },
+	verbEnum:     func() interface{} { return new(string) },
+	verbTime:     func() interface{} { return new(time.Time) },
+}
+
+// Overrides of newTargetFuncs for ScanValues, whose '%d' values are ints rather than int64s as for ScanMap.
+var valueTargetFuncs = map[string]func() interface{}{
+	verbInt: func() interface{} { return new(int) },
+}
+
+// Sets the value 'target' points to to its zero value, e.g. nil for a pointer-to-pointer target.
+func setZero(target interface{}) error {
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return fmt.Errorf("expected pointer as target, got %T", target)
+	}
+
+	ptr.Elem().Set(reflect.Zero(ptr.Elem().Type()))
+	return nil
 }
 
 func (rns runes) excludes(r rune) bool {
@@ -36,7 +95,7 @@ func (rns runes) excludes(r rune) bool {
 	return true
 }
 
-func assignBool(str string, target interface{}) (int, error) {
+func assignBool(str string, target interface{}, _ verb) (int, error) {
 	pBool, ok := target.(*bool)
 	if !ok {
 		return 0, fmt.Errorf("expected bool pointer as target, got %T", target)
@@ -59,7 +118,51 @@ func assignBool(str string, target interface{}) (int, error) {
 	return len(str), nil
 }
 
-func assignString(str string, target interface{}) (int, error) {
+type boolWord struct {
+	word  string
+	value bool
+}
+
+// Returns an assign func for bool targets that accepts only the given words.
+func newBoolWordsAssignFunc(boolWords []boolWord) assignFunc {
+	// Try longer words first, so that e.g. 'no' doesn't shadow 'none'.
+	words := make([]boolWord, len(boolWords))
+	copy(words, boolWords)
+	sort.SliceStable(words, func(i, j int) bool {
+		return len(words[i].word) > len(words[j].word)
+	})
+
+	return func(str string, target interface{}, _ verb) (int, error) {
+		pBool, ok := target.(*bool)
+		if !ok {
+			return 0, fmt.Errorf("expected bool pointer as target, got %T", target)
+		}
+
+		for _, bw := range words {
+			n := len(bw.word)
+			if len(str) < n || !strings.EqualFold(str[:n], bw.word) || !isWordBoundary(str[n:]) {
+				continue
+			}
+
+			*pBool = bw.value
+			return n, nil
+		}
+
+		return 0, fmt.Errorf("expected leading bool word, got '%s'", str)
+	}
+}
+
+// Reports whether 'str' begins with a character that can't continue a word, or is empty.
+func isWordBoundary(str string) bool {
+	r, size := utf8.DecodeRuneInString(str)
+	if size == 0 {
+		return true
+	}
+
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+func assignString(str string, target interface{}, _ verb) (int, error) {
 	pStr, ok := target.(*string)
 	if !ok {
 		return 0, fmt.Errorf("expected string pointer as target, got %T", target)
@@ -69,7 +172,7 @@ func assignString(str string, target interface{}) (int, error) {
 	return len(str), nil
 }
 
-func assignInt(str string, target interface{}) (int, error) {
+func assignInt(str string, target interface{}, _ verb) (int, error) {
 	var signed int64
 	var unsigned uint64
 	var err error
@@ -123,3 +226,164 @@ func assignInt(str string, target interface{}) (int, error) {
 
 	return len(str), nil
 }
+
+// Sets an integer or float target to 'value', which must be whole for the former.
+func setNumber(target interface{}, value *big.Rat) error {
+	switch v := target.(type) {
+	case *float32:
+		f, _ := value.Float32()
+		if math.IsInf(float64(f), 0) {
+			return strconv.ErrRange
+		}
+
+		*v = f
+		return nil
+	case *float64:
+		f, _ := value.Float64()
+		if math.IsInf(f, 0) {
+			return strconv.ErrRange
+		}
+
+		*v = f
+		return nil
+	}
+
+	if !isIntPointer(target) {
+		return fmt.Errorf("expected integer or float pointer as target, got %T", target)
+	}
+
+	if !value.IsInt() {
+		return fmt.Errorf("%s is not a whole number", value.FloatString(3))
+	}
+
+	return setInt(target, value.Num())
+}
+
+func isIntPointer(target interface{}) bool {
+	switch target.(type) {
+	case *int, *int8, *int16, *int32, *int64, *uint, *uint8, *uint16, *uint32, *uint64:
+		return true
+	}
+
+	return false
+}
+
+// Sets an integer target to 'value', which must fit in the target's type.
+func setInt(target interface{}, value *big.Int) error {
+	var bitSize int
+	var signed bool
+
+	switch target.(type) {
+	case *int:
+		bitSize, signed = 0, true
+	case *int8:
+		bitSize, signed = 8, true
+	case *int16:
+		bitSize, signed = 16, true
+	case *int32:
+		bitSize, signed = 32, true
+	case *int64:
+		bitSize, signed = 64, true
+	case *uint:
+		bitSize = 0
+	case *uint8:
+		bitSize = 8
+	case *uint16:
+		bitSize = 16
+	case *uint32:
+		bitSize = 32
+	case *uint64:
+		bitSize = 64
+	default:
+		return fmt.Errorf("expected integer pointer as target, got %T", target)
+	}
+
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+
+	if signed {
+		if !value.IsInt64() {
+			return strconv.ErrRange
+		}
+
+		i := value.Int64()
+		if bitSize < 64 && (i < -1<<(bitSize-1) || i > 1<<(bitSize-1)-1) {
+			return strconv.ErrRange
+		}
+
+		switch v := target.(type) {
+		case *int:
+			*v = int(i)
+		case *int8:
+			*v = int8(i)
+		case *int16:
+			*v = int16(i)
+		case *int32:
+			*v = int32(i)
+		case *int64:
+			*v = i
+		}
+
+		return nil
+	}
+
+	if !value.IsUint64() {
+		return strconv.ErrRange
+	}
+
+	u := value.Uint64()
+	if bitSize < 64 && u > uint64(1)<<bitSize-1 {
+		return strconv.ErrRange
+	}
+
+	switch v := target.(type) {
+	case *uint:
+		*v = uint(u)
+	case *uint8:
+		*v = uint8(u)
+	case *uint16:
+		*v = uint16(u)
+	case *uint32:
+		*v = uint32(u)
+	case *uint64:
+		*v = u
+	}
+
+	return nil
+}
+
+/*
+Assigns the longest of an enum verb's values found at the start of 'str'
+to a string target, or its index among the values to an integer target.
+*/
+func assignEnum(str string, target interface{}, v verb) (int, error) {
+	index := -1
+	var match string
+
+	for i, value := range v.enumValues() {
+		if len(value) > len(match) && strings.HasPrefix(str, value) {
+			index, match = i, value
+		}
+	}
+
+	if index < 0 {
+		return 0, fmt.Errorf("expected leading one of '%s', got '%s'", v.options, str)
+	}
+
+	if pStr, ok := target.(*string); ok {
+		*pStr = match
+		return len(match), nil
+	}
+
+	if !isIntPointer(target) {
+		return 0, fmt.Errorf("expected string or integer pointer as target, got %T", target)
+	}
+
+	err := setInt(target, big.NewInt(int64(index)))
+	if err != nil {
+		return 0, fmt.Errorf("error converting '%s' to enum index: %w", match, err)
+	}
+
+	return len(match), nil
+}
@@ -0,0 +1,137 @@
+package unfmt
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+Assigns a CSS-like color, in one of the forms '#rgb', '#rrggbb', '#rrggbbaa'
+or 'rgb(r, g, b)', to a color.NRGBA, color.RGBA or validated string target.
+A color.RGBA target receives the color alpha-premultiplied.
+*/
+func assignColor(str string, target interface{}, _ verb) (int, error) {
+	var c color.NRGBA
+	var n int
+	var err error
+
+	if strings.HasPrefix(str, "#") {
+		c, n, err = scanHexColor(str)
+	} else {
+		c, n, err = scanRGBColor(str)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := target.(type) {
+	case *color.NRGBA:
+		*v = c
+	case *color.RGBA:
+		*v = color.RGBAModel.Convert(c).(color.RGBA)
+	case *string:
+		*v = str[:n]
+	default:
+		return 0, fmt.Errorf("expected color.NRGBA, color.RGBA or string pointer as target, got %T", target)
+	}
+
+	return n, nil
+}
+
+func scanHexColor(str string) (color.NRGBA, int, error) {
+	digits := runSpan(str[1:], hexRunes)
+
+	var expand bool
+	switch {
+	case digits >= 8:
+		digits = 8
+	case digits >= 6:
+		digits = 6
+	case digits >= 3:
+		digits = 3
+		expand = true
+	default:
+		return color.NRGBA{}, 0, fmt.Errorf("expected leading hex color, got '%s'", str)
+	}
+
+	hex := str[1 : 1+digits]
+	if expand {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	rgba, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, 0, fmt.Errorf("error converting '%s' to color: %w", str[:1+digits], err)
+	}
+
+	return color.NRGBA{
+		R: uint8(rgba >> 24),
+		G: uint8(rgba >> 16),
+		B: uint8(rgba >> 8),
+		A: uint8(rgba),
+	}, 1 + digits, nil
+}
+
+func scanRGBColor(str string) (color.NRGBA, int, error) {
+	const prefix = "rgb("
+	if !strings.HasPrefix(str, prefix) {
+		return color.NRGBA{}, 0, fmt.Errorf("expected leading hex or rgb() color, got '%s'", str)
+	}
+
+	i := len(prefix)
+
+	var components [3]uint8
+	for c := range components {
+		i += spaceSpan(str[i:])
+
+		if c > 0 {
+			if i == len(str) || str[i] != ',' {
+				return color.NRGBA{}, 0, fmt.Errorf("expected ',' between rgb() components in '%s'", str)
+			}
+
+			i++
+			i += spaceSpan(str[i:])
+		}
+
+		n := runSpan(str[i:], digitRunes)
+		component, err := strconv.ParseUint(str[i:i+n], 10, 8)
+		if err != nil {
+			return color.NRGBA{}, 0, fmt.Errorf("error converting rgb() component in '%s': %w", str, err)
+		}
+
+		components[c] = uint8(component)
+		i += n
+	}
+
+	i += spaceSpan(str[i:])
+	if i == len(str) || str[i] != ')' {
+		return color.NRGBA{}, 0, fmt.Errorf("expected ')' after rgb() components in '%s'", str)
+	}
+
+	return color.NRGBA{
+		R: components[0],
+		G: components[1],
+		B: components[2],
+		A: 0xff,
+	}, i + 1, nil
+}
+
+// Returns the length of the whitespace at the start of 'str'.
+func spaceSpan(str string) int {
+	n := strings.IndexFunc(str, func(r rune) bool {
+		return !unicode.IsSpace(r)
+	})
+	if n < 0 {
+		return len(str)
+	}
+
+	return n
+}
@@ -0,0 +1,59 @@
+package unfmt
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+)
+
+/*
+Assigns an exact decimal number, e.g. '1234.56', to an integer, big.Rat,
+encoding.TextUnmarshaler or validated string target, without going through
+a float.
+
+The verb's precision flag declares the scale, e.g. '%.2m' for cents, and
+numbers with more fractional digits than that are rejected. Integer targets
+receive the number in minor units, i.e. scaled by ten to the power of the
+precision, so '1234.5' is assigned as 123450 by '%.2m'.
+*/
+func assignDecimal(str string, target interface{}, v verb) (int, error) {
+	n := decimalSpan(str)
+	if n == 0 {
+		return 0, fmt.Errorf("expected one or more leading numeric characters, got '%s'", str)
+	}
+
+	str = str[:n]
+
+	precision, hasPrecision := v.precision()
+	if _, fracPart := splitDecimal(str); hasPrecision && len(fracPart) > precision {
+		return 0, fmt.Errorf("'%s' has %d fractional digits, more than precision of %d", str, len(fracPart), precision)
+	}
+
+	r, ok := new(big.Rat).SetString(str)
+	if !ok {
+		return 0, fmt.Errorf("error converting '%s' to decimal", str)
+	}
+
+	switch t := target.(type) {
+	case *big.Rat:
+		t.Set(r)
+	case encoding.TextUnmarshaler:
+		err := t.UnmarshalText([]byte(str))
+		if err != nil {
+			return 0, fmt.Errorf("error converting '%s' to decimal: %w", str, err)
+		}
+	case *string:
+		*t = str
+	case *int, *int8, *int16, *int32, *int64, *uint, *uint8, *uint16, *uint32, *uint64:
+		minorUnits := r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)))
+
+		err := setNumber(target, minorUnits)
+		if err != nil {
+			return 0, fmt.Errorf("error converting '%s' to decimal: %w", str, err)
+		}
+	default:
+		return 0, fmt.Errorf("expected integer, big.Rat, encoding.TextUnmarshaler or string pointer as target, got %T", target)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,219 @@
+package unfmt
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+const (
+	ipRunes   runes = hexRunes + ":."
+	zoneRunes runes = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_.-"
+	macRunes  runes = hexRunes + ":.-"
+)
+
+/*
+Assigns an IPv4 or IPv6 address, with an optional zone for the latter,
+to a net.IP, netip.Addr or validated string target. Any zone is dropped
+for a net.IP target, which can't hold one.
+*/
+func assignIP(str string, target interface{}, _ verb) (int, error) {
+	n := ipSpan(str)
+	if n == 0 {
+		return 0, fmt.Errorf("expected leading IP address, got '%s'", str)
+	}
+
+	addr, err := netip.ParseAddr(str[:n])
+	if err != nil {
+		return 0, fmt.Errorf("error converting '%s' to IP address: %w", str[:n], err)
+	}
+
+	switch v := target.(type) {
+	case *netip.Addr:
+		*v = addr
+	case *net.IP:
+		*v = net.IP(addr.AsSlice())
+	case *string:
+		*v = str[:n]
+	default:
+		return 0, fmt.Errorf("expected netip.Addr, net.IP or string pointer as target, got %T", target)
+	}
+
+	return n, nil
+}
+
+// Assigns a CIDR prefix to a netip.Prefix, net.IPNet or validated string target.
+func assignPrefix(str string, target interface{}, _ verb) (int, error) {
+	n := prefixSpan(str)
+	if n == 0 {
+		return 0, fmt.Errorf("expected leading CIDR prefix, got '%s'", str)
+	}
+
+	prefix, err := netip.ParsePrefix(str[:n])
+	if err != nil {
+		return 0, fmt.Errorf("error converting '%s' to CIDR prefix: %w", str[:n], err)
+	}
+
+	switch v := target.(type) {
+	case *netip.Prefix:
+		*v = prefix
+	case *net.IPNet:
+		*v = net.IPNet{
+			IP:   net.IP(prefix.Addr().AsSlice()),
+			Mask: net.CIDRMask(prefix.Bits(), prefix.Addr().BitLen()),
+		}
+	case *string:
+		*v = str[:n]
+	default:
+		return 0, fmt.Errorf("expected netip.Prefix, net.IPNet or string pointer as target, got %T", target)
+	}
+
+	return n, nil
+}
+
+/*
+Assigns an IP address and port pair, e.g. '10.0.0.1:443' or '[2001:db8::1]:443',
+to a netip.AddrPort or validated string target.
+*/
+func assignAddrPort(str string, target interface{}, _ verb) (int, error) {
+	n := addrPortSpan(str)
+	if n == 0 {
+		return 0, fmt.Errorf("expected leading IP address and port, got '%s'", str)
+	}
+
+	addrPort, err := netip.ParseAddrPort(str[:n])
+	if err != nil {
+		return 0, fmt.Errorf("error converting '%s' to IP address and port: %w", str[:n], err)
+	}
+
+	switch v := target.(type) {
+	case *netip.AddrPort:
+		*v = addrPort
+	case *string:
+		*v = str[:n]
+	default:
+		return 0, fmt.Errorf("expected netip.AddrPort or string pointer as target, got %T", target)
+	}
+
+	return n, nil
+}
+
+// Assigns a hardware address in any form accepted by net.ParseMAC to a net.HardwareAddr or validated string target.
+func assignMAC(str string, target interface{}, _ verb) (int, error) {
+	// The longest valid prefix is the last one parsed.
+	var mac net.HardwareAddr
+	n := longestValidPrefix(str[:runSpan(str, macRunes)], func(s string) bool {
+		var err error
+		mac, err = net.ParseMAC(s)
+		return err == nil
+	})
+	if n == 0 {
+		return 0, fmt.Errorf("expected leading hardware address, got '%s'", str)
+	}
+
+	switch v := target.(type) {
+	case *net.HardwareAddr:
+		*v = mac
+	case *string:
+		*v = str[:n]
+	default:
+		return 0, fmt.Errorf("expected net.HardwareAddr or string pointer as target, got %T", target)
+	}
+
+	return n, nil
+}
+
+// Returns the length of the IP address, including any IPv6 zone, at the start of 'str'.
+func ipSpan(str string) int {
+	n := longestValidPrefix(str[:runSpan(str, ipRunes)], isIP)
+	if n == 0 {
+		return 0
+	}
+
+	if n < len(str) && str[n] == '%' && strings.Contains(str[:n], ":") {
+		if zoneLen := runSpan(str[n+1:], zoneRunes); zoneLen > 0 && isIP(str[:n+1+zoneLen]) {
+			n += 1 + zoneLen
+		}
+	}
+
+	return n
+}
+
+// Returns the length of the CIDR prefix at the start of 'str'.
+func prefixSpan(str string) int {
+	n := ipSpan(str)
+	if n == 0 || n == len(str) || str[n] != '/' {
+		return 0
+	}
+
+	bitsLen := runSpan(str[n+1:], digitRunes)
+	if bitsLen > 3 {
+		bitsLen = 3
+	}
+
+	return longestValidPrefix(str[:n+1+bitsLen], func(s string) bool {
+		_, err := netip.ParsePrefix(s)
+		return err == nil
+	})
+}
+
+// Returns the length of the IP address and port pair at the start of 'str'.
+func addrPortSpan(str string) int {
+	var n int
+	if len(str) > 0 && str[0] == '[' {
+		n = ipSpan(str[1:])
+		if n == 0 || 1+n == len(str) || str[1+n] != ']' {
+			return 0
+		}
+
+		n += 2
+	} else {
+		n = ipSpan(str)
+
+		// A bare IPv6 address would swallow the port.
+		if n == 0 || strings.Contains(str[:n], ":") {
+			return 0
+		}
+	}
+
+	if n == len(str) || str[n] != ':' {
+		return 0
+	}
+
+	portLen := runSpan(str[n+1:], digitRunes)
+	if portLen > 5 {
+		portLen = 5
+	}
+
+	return longestValidPrefix(str[:n+1+portLen], func(s string) bool {
+		_, err := netip.ParseAddrPort(s)
+		return err == nil
+	})
+}
+
+func isIP(str string) bool {
+	_, err := netip.ParseAddr(str)
+	return err == nil
+}
+
+// Returns the length of the run of 'rns' at the start of 'str'.
+func runSpan(str string, rns runes) int {
+	n := strings.IndexFunc(str, rns.excludes)
+	if n < 0 {
+		return len(str)
+	}
+
+	return n
+}
+
+// Returns the length of the longest prefix of 'str' accepted by 'valid', or 0 if none.
+func longestValidPrefix(str string, valid func(string) bool) int {
+	for n := len(str); n > 0; n-- {
+		if valid(str[:n]) {
+			return n
+		}
+	}
+
+	return 0
+}
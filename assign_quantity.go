@@ -0,0 +1,81 @@
+package unfmt
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Unit prefixes in ascending order of magnitude, each a power of 1000 per SI or 1024 per IEC.
+const quantityPrefixes = "KMGTPE"
+
+/*
+Assigns a number with an optional SI or IEC unit prefix, e.g. '3.2M', '512K'
+or '1.5GiB', scaled accordingly to an integer or float target. The prefix may
+be followed by a 'B' for bytes, which doesn't affect the value.
+
+IEC prefixes like 'Ki' are powers of 1024, and SI prefixes like 'k' or 'K' are
+powers of 1000 unless the verb has the '#' flag, e.g. '%#H', in which case they
+are taken as powers of 1024 too, as is common for memory sizes.
+
+Integer targets require the scaled value to be a whole number.
+*/
+func assignQuantity(str string, target interface{}, v verb) (int, error) {
+	n := decimalSpan(str)
+	if n == 0 {
+		return 0, fmt.Errorf("expected one or more leading numeric characters, got '%s'", str)
+	}
+
+	value, ok := new(big.Rat).SetString(str[:n])
+	if !ok {
+		return 0, fmt.Errorf("error converting '%s' to quantity", str[:n])
+	}
+
+	prefixLen, exponent, binary := quantityPrefixSpan(str[n:])
+	if v.hasFlag('#') {
+		binary = true
+	}
+
+	base := int64(1000)
+	if binary {
+		base = 1024
+	}
+
+	multiplier := new(big.Int).Exp(big.NewInt(base), big.NewInt(int64(exponent)), nil)
+	value.Mul(value, new(big.Rat).SetInt(multiplier))
+
+	n += prefixLen
+	if n < len(str) && str[n] == 'B' {
+		n++
+	}
+
+	err := setNumber(target, value)
+	if err != nil {
+		return 0, fmt.Errorf("error converting '%s' to quantity: %w", str[:n], err)
+	}
+
+	return n, nil
+}
+
+// Returns the length, exponent and base of the unit prefix at the start of 'str', if any.
+func quantityPrefixSpan(str string) (n, exponent int, binary bool) {
+	if len(str) == 0 {
+		return 0, 0, false
+	}
+
+	prefix := str[0]
+	if prefix == 'k' {
+		prefix = 'K'
+	}
+
+	i := strings.IndexByte(quantityPrefixes, prefix)
+	if i < 0 {
+		return 0, 0, false
+	}
+
+	if len(str) > 1 && str[1] == 'i' {
+		return 2, i + 1, true
+	}
+
+	return 1, i + 1, false
+}
@@ -0,0 +1,443 @@
+package unfmt
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxEpochPrecision is the number of fractional digits of a second
+// representable by time.Time, i.e. nanoseconds.
+const maxEpochPrecision = 9
+
+/*
+Assigns a Unix timestamp to a time.Time target, in UTC.
+
+The verb's precision flag declares the unit of the integer part
+as a power of ten fraction of a second: '%T' or '%.0T' for seconds,
+'%.3T' for milliseconds, '%.6T' for microseconds and '%.9T' for
+nanoseconds. Any fractional digits after a '.' in 'str' are taken
+as a fraction of that unit, down to the nanosecond.
+*/
+func assignEpoch(str string, target interface{}, v verb) (int, error) {
+	pTime, ok := target.(*time.Time)
+	if !ok {
+		return 0, fmt.Errorf("expected time.Time pointer as target, got %T", target)
+	}
+
+	precision, _ := v.precision()
+	if precision > maxEpochPrecision {
+		return 0, fmt.Errorf("precision %d exceeds max of %d for Unix timestamp", precision, maxEpochPrecision)
+	}
+
+	n := decimalSpan(str)
+	if n == 0 {
+		return 0, fmt.Errorf("expected one or more leading numeric characters, got '%s'", str)
+	}
+
+	str = str[:n]
+
+	negative := str[0] == '-'
+	intPart, fracPart := splitDecimal(str)
+
+	units, err := strconv.ParseUint(intPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error converting '%s' to Unix timestamp: %w", str, err)
+	}
+
+	unitsPerSecond := pow10(precision)
+	nanosPerUnit := pow10(maxEpochPrecision - precision)
+
+	if units/unitsPerSecond > math.MaxInt64 {
+		return 0, fmt.Errorf("error converting '%s' to Unix timestamp: %w", str, strconv.ErrRange)
+	}
+
+	sec := int64(units / unitsPerSecond)
+	nsec := int64(units%unitsPerSecond) * int64(nanosPerUnit)
+
+	// Any fractional digits beyond nanosecond resolution are truncated.
+	if maxFracDigits := maxEpochPrecision - precision; len(fracPart) > maxFracDigits {
+		fracPart = fracPart[:maxFracDigits]
+	}
+
+	if len(fracPart) > 0 {
+		frac, err := strconv.ParseUint(fracPart, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("error converting '%s' to Unix timestamp: %w", str, err)
+		}
+
+		nsec += int64(frac * (nanosPerUnit / pow10(len(fracPart))))
+	}
+
+	if negative {
+		sec, nsec = -sec, -nsec
+	}
+
+	*pTime = time.Unix(sec, nsec).UTC()
+	return n, nil
+}
+
+/*
+Assigns a time in the layout declared by a long-form verb, e.g. '%{time:2006-01-02}',
+or in RFC 3339 if none, to a time.Time or validated string target. The longest
+prefix of 'str' that parses in the layout is evaluated, so that the verb can be
+adjacent to others.
+*/
+func assignTime(str string, target interface{}, v verb) (int, error) {
+	layout := v.timeLayout()
+
+	// The longest valid prefix is the last one parsed.
+	var parsed time.Time
+	n := longestValidPrefix(str, func(s string) bool {
+		var err error
+		parsed, err = time.Parse(layout, s)
+		return err == nil
+	})
+	if n == 0 {
+		return 0, fmt.Errorf("expected leading time in layout '%s', got '%s'", layout, str)
+	}
+
+	switch t := target.(type) {
+	case *time.Time:
+		*t = parsed
+	case *string:
+		*t = str[:n]
+	default:
+		return 0, fmt.Errorf("expected time.Time or string pointer as target, got %T", target)
+	}
+
+	return n, nil
+}
+
+// Returns the length of the signed decimal number, with optional
+// fractional part, at the start of 'str'.
+func decimalSpan(str string) int {
+	var i int
+	if i < len(str) && (str[i] == '+' || str[i] == '-') {
+		i++
+	}
+
+	digitsFrom := i
+	for i < len(str) && isDigit(str[i]) {
+		i++
+	}
+
+	if i == digitsFrom {
+		return 0
+	}
+
+	if i+1 < len(str) && str[i] == '.' && isDigit(str[i+1]) {
+		i++
+		for i < len(str) && isDigit(str[i]) {
+			i++
+		}
+	}
+
+	return i
+}
+
+// Splits a signed decimal number into its unsigned integer and fractional digits.
+func splitDecimal(str string) (intPart, fracPart string) {
+	if len(str) > 0 && (str[0] == '+' || str[0] == '-') {
+		str = str[1:]
+	}
+
+	for i := 0; i < len(str); i++ {
+		if str[i] == '.' {
+			return str[:i], str[i+1:]
+		}
+	}
+
+	return str, ""
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func pow10(n int) uint64 {
+	p := uint64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+
+	return p
+}
+
+var goDurationUnits = []string{"ns", "us", "µs", "μs", "ms", "s", "m", "h"}
+
+/*
+Assigns a duration to a time.Duration target, either in the syntax
+accepted by time.ParseDuration, e.g. '1h2m3.5s' or '250ms', or as
+an ISO-8601 duration, e.g. 'PT1H30M' or 'P1DT12H'.
+
+Only as much of 'str' as forms a valid duration is evaluated, so that
+any trailing characters are left for adjacent verbs. ISO-8601 years
+and months are rejected since they have no fixed length.
+*/
+func assignDuration(str string, target interface{}, _ verb) (int, error) {
+	pDuration, ok := target.(*time.Duration)
+	if !ok {
+		return 0, fmt.Errorf("expected time.Duration pointer as target, got %T", target)
+	}
+
+	if n := isoDurationSpan(str); n > 0 {
+		d, err := parseISODuration(str[:n])
+		if err != nil {
+			return 0, fmt.Errorf("error converting '%s' to duration: %w", str[:n], err)
+		}
+
+		*pDuration = d
+		return n, nil
+	}
+
+	n := goDurationSpan(str)
+	if n == 0 {
+		return 0, fmt.Errorf("expected leading duration, got '%s'", str)
+	}
+
+	d, err := time.ParseDuration(str[:n])
+	if err != nil {
+		return 0, fmt.Errorf("error converting '%s' to duration: %w", str[:n], err)
+	}
+
+	*pDuration = d
+	return n, nil
+}
+
+// Returns the length of the duration in time.ParseDuration syntax at the start of 'str'.
+func goDurationSpan(str string) int {
+	var i int
+	if i < len(str) && (str[i] == '+' || str[i] == '-') {
+		i++
+	}
+
+	var end int
+	for i < len(str) {
+		n := durationNumberSpan(str[i:])
+		if n == 0 {
+			break
+		}
+
+		unitLen := durationUnitSpan(str[i+n:])
+		if unitLen == 0 {
+			// As in time.ParseDuration, a lone zero needs no unit.
+			if end == 0 && str[i:i+n] == "0" {
+				end = i + n
+			}
+
+			break
+		}
+
+		i += n + unitLen
+		end = i
+	}
+
+	return end
+}
+
+// Returns the length of the number at the start of 'str', which may have
+// digits on either side of a single '.' as in time.ParseDuration.
+func durationNumberSpan(str string) int {
+	var i, digits int
+	var dot bool
+
+	for ; i < len(str); i++ {
+		if isDigit(str[i]) {
+			digits++
+			continue
+		}
+
+		if str[i] != '.' || dot {
+			break
+		}
+
+		dot = true
+	}
+
+	if digits == 0 {
+		return 0
+	}
+
+	return i
+}
+
+// Returns the length of the longest time.ParseDuration unit at the start of 'str'.
+func durationUnitSpan(str string) int {
+	var longest int
+	for _, unit := range goDurationUnits {
+		if len(unit) > longest && strings.HasPrefix(str, unit) {
+			longest = len(unit)
+		}
+	}
+
+	return longest
+}
+
+// Returns the length of the ISO-8601 duration at the start of 'str'.
+func isoDurationSpan(str string) int {
+	var i int
+	if i < len(str) && (str[i] == '+' || str[i] == '-') {
+		i++
+	}
+
+	if i == len(str) || str[i] != 'P' {
+		return 0
+	}
+
+	i++
+
+	end := isoComponentsSpan(str, i, "YMWD")
+	if end > 0 {
+		i = end
+	}
+
+	if i < len(str) && str[i] == 'T' {
+		if timeEnd := isoComponentsSpan(str, i+1, "HMS"); timeEnd > 0 {
+			end = timeEnd
+		}
+	}
+
+	return end
+}
+
+// Consumes components of 'str' from index 'i', each a number followed by the
+// next of 'designators' in order, and returns the index after the last one.
+// Returns 0 if there are no components.
+func isoComponentsSpan(str string, i int, designators string) int {
+	var end int
+	for i < len(str) {
+		n := isoNumberSpan(str[i:])
+		if n == 0 || i+n == len(str) {
+			break
+		}
+
+		designatorIndex := strings.IndexByte(designators, str[i+n])
+		if designatorIndex < 0 {
+			break
+		}
+
+		designators = designators[designatorIndex+1:]
+		i += n + 1
+		end = i
+	}
+
+	return end
+}
+
+// Returns the length of the ISO-8601 number at the start of 'str', which may
+// use either '.' or ',' for a decimal separator.
+func isoNumberSpan(str string) int {
+	var i int
+	for i < len(str) && isDigit(str[i]) {
+		i++
+	}
+
+	if i == 0 {
+		return 0
+	}
+
+	if i+1 < len(str) && (str[i] == '.' || str[i] == ',') && isDigit(str[i+1]) {
+		i++
+		for i < len(str) && isDigit(str[i]) {
+			i++
+		}
+	}
+
+	return i
+}
+
+func isoDesignatorUnit(designator byte, inTime bool) (time.Duration, bool) {
+	if inTime {
+		switch designator {
+		case 'H':
+			return time.Hour, true
+		case 'M':
+			return time.Minute, true
+		case 'S':
+			return time.Second, true
+		}
+
+		return 0, false
+	}
+
+	switch designator {
+	case 'W':
+		return 7 * 24 * time.Hour, true
+	case 'D':
+		return 24 * time.Hour, true
+	}
+
+	return 0, false
+}
+
+// Parses an ISO-8601 duration as found by isoDurationSpan.
+func parseISODuration(str string) (time.Duration, error) {
+	var negative bool
+	switch str[0] {
+	case '-':
+		negative = true
+		fallthrough
+	case '+':
+		str = str[1:]
+	}
+
+	// Skip the leading 'P'.
+	str = str[1:]
+
+	var d time.Duration
+	var inTime bool
+
+	for len(str) > 0 {
+		if str[0] == 'T' {
+			inTime = true
+			str = str[1:]
+			continue
+		}
+
+		n := isoNumberSpan(str)
+		number, designator := strings.Replace(str[:n], ",", ".", 1), str[n]
+		str = str[n+1:]
+
+		unit, ok := isoDesignatorUnit(designator, inTime)
+		if !ok {
+			return 0, fmt.Errorf("designator '%c' has no fixed duration", designator)
+		}
+
+		intPart, fracPart := splitDecimal(number)
+
+		units, err := strconv.ParseInt(intPart, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+
+		if units > int64(math.MaxInt64/unit) {
+			return 0, strconv.ErrRange
+		}
+
+		component := time.Duration(units) * unit
+
+		if len(fracPart) > 0 {
+			frac, err := strconv.ParseFloat("0."+fracPart, 64)
+			if err != nil {
+				return 0, err
+			}
+
+			component += time.Duration(frac * float64(unit))
+		}
+
+		if d > math.MaxInt64-component {
+			return 0, strconv.ErrRange
+		}
+
+		d += component
+	}
+
+	if negative {
+		d = -d
+	}
+
+	return d, nil
+}
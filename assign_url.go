@@ -0,0 +1,64 @@
+package unfmt
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// URL characters besides alphanumerics and percent-encodings, per RFC 3986.
+const urlRunes runes = "-._~:/?#[]@!$&'()*+,;="
+
+/*
+Assigns an absolute or relative URL to a url.URL or validated string target.
+
+Only as much of 'str' is evaluated as consists of characters allowed in a URL,
+so that the verb stops at e.g. a space or quote, with any '%' required to begin
+a valid percent-encoding.
+*/
+func assignURL(str string, target interface{}, _ verb) (int, error) {
+	n := urlSpan(str)
+	if n == 0 {
+		return 0, fmt.Errorf("expected leading URL, got '%s'", str)
+	}
+
+	u, err := url.Parse(str[:n])
+	if err != nil {
+		return 0, fmt.Errorf("error converting '%s' to URL: %w", str[:n], err)
+	}
+
+	switch v := target.(type) {
+	case *url.URL:
+		*v = *u
+	case *string:
+		*v = str[:n]
+	default:
+		return 0, fmt.Errorf("expected url.URL or string pointer as target, got %T", target)
+	}
+
+	return n, nil
+}
+
+// Returns the length of the run of URL characters at the start of 'str'.
+func urlSpan(str string) int {
+	var i int
+	for i < len(str) {
+		b := str[i]
+
+		switch {
+		case isDigit(b), b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z':
+			i++
+		case b == '%':
+			if i+2 >= len(str) || hexRunes.excludes(rune(str[i+1])) || hexRunes.excludes(rune(str[i+2])) {
+				return i
+			}
+
+			i += 3
+		case urlRunes.includes(rune(b)):
+			i++
+		default:
+			return i
+		}
+	}
+
+	return i
+}
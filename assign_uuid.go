@@ -0,0 +1,88 @@
+package unfmt
+
+import (
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Lengths of the hex digit groups in a canonical UUID, e.g. 'f81d4fae-7dec-11d0-a765-00a0c91e6bf6'.
+var uuidGroupLens = []int{8, 4, 4, 4, 12}
+
+/*
+Assigns a UUID in canonical, braced or compact hex form to a [16]byte,
+encoding.TextUnmarshaler or validated string target. Exactly as many
+characters of 'str' as form the UUID are evaluated, so that the verb
+can be adjacent to others.
+*/
+func assignUUID(str string, target interface{}, _ verb) (int, error) {
+	n := uuidSpan(str)
+	if n == 0 {
+		return 0, fmt.Errorf("expected leading UUID, got '%s'", str)
+	}
+
+	switch v := target.(type) {
+	case encoding.TextUnmarshaler:
+		err := v.UnmarshalText([]byte(str[:n]))
+		if err != nil {
+			return 0, fmt.Errorf("error converting '%s' to UUID: %w", str[:n], err)
+		}
+	case *[16]byte:
+		digits := strings.Trim(strings.ReplaceAll(str[:n], "-", ""), "{}")
+
+		_, err := hex.Decode(v[:], []byte(digits))
+		if err != nil {
+			return 0, fmt.Errorf("error converting '%s' to UUID: %w", str[:n], err)
+		}
+	case *string:
+		*v = str[:n]
+	default:
+		return 0, fmt.Errorf("expected [16]byte, encoding.TextUnmarshaler or string pointer as target, got %T", target)
+	}
+
+	return n, nil
+}
+
+// Returns the length of the UUID at the start of 'str'.
+func uuidSpan(str string) int {
+	if n := canonicalUUIDSpan(str); n > 0 {
+		return n
+	}
+
+	if len(str) > 0 && str[0] == '{' {
+		if n := canonicalUUIDSpan(str[1:]); n > 0 && 1+n < len(str) && str[1+n] == '}' {
+			return n + 2
+		}
+
+		return 0
+	}
+
+	const compactLen = 32
+	if len(str) >= compactLen && strings.IndexFunc(str[:compactLen], hexRunes.excludes) < 0 {
+		return compactLen
+	}
+
+	return 0
+}
+
+func canonicalUUIDSpan(str string) int {
+	var i int
+	for groupIndex, groupLen := range uuidGroupLens {
+		if groupIndex > 0 {
+			if i == len(str) || str[i] != '-' {
+				return 0
+			}
+
+			i++
+		}
+
+		if len(str) < i+groupLen || strings.IndexFunc(str[i:i+groupLen], hexRunes.excludes) >= 0 {
+			return 0
+		}
+
+		i += groupLen
+	}
+
+	return i
+}
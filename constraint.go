@@ -0,0 +1,129 @@
+package unfmt
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+)
+
+// ConstraintError reports a capture that violates a constraint declared for its verb in 'format'.
+type ConstraintError struct {
+	// Verb is the verb as written in 'format', e.g. '%{d:1..65535}'.
+	Verb string
+
+	// Text is the captured text.
+	Text string
+
+	// Constraint is the violated constraint, e.g. '1..65535'.
+	Constraint string
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("'%s' captured for verb '%s' violates constraint '%s'", e.Text, e.Verb, e.Constraint)
+}
+
+/*
+A constraint on a verb's capture, declared in its long-form options as a range
+of values 'min..max', e.g. '%{d:1..65535}', or of lengths in characters with
+'len=min..max', e.g. '%{s:len=3..16}'. Either bound may be omitted to leave
+that side unbounded, and a single number constrains to exactly that value or
+length, e.g. 'len=3'. Multiple constraints are separated by ','.
+*/
+type constraint struct {
+	source   string
+	length   bool
+	min, max *big.Rat
+}
+
+func (c constraint) String() string {
+	return c.source
+}
+
+func parseConstraints(options string) ([]constraint, error) {
+	var constraints []constraint
+
+	for _, source := range splitUnescaped(options, ',') {
+		c := constraint{source: source}
+
+		bounds := source
+		if strings.HasPrefix(bounds, "len=") {
+			c.length = true
+			bounds = strings.TrimPrefix(bounds, "len=")
+		}
+
+		min, max := bounds, bounds
+		if i := strings.Index(bounds, ".."); i >= 0 {
+			min, max = bounds[:i], bounds[i+len(".."):]
+		}
+
+		var err error
+		c.min, err = parseBound(min)
+		if err != nil {
+			return nil, fmt.Errorf("has bad constraint '%s': %w", source, err)
+		}
+
+		c.max, err = parseBound(max)
+		if err != nil {
+			return nil, fmt.Errorf("has bad constraint '%s': %w", source, err)
+		}
+
+		if c.min == nil && c.max == nil {
+			return nil, fmt.Errorf("has bad constraint '%s': no bounds", source)
+		}
+
+		if c.min != nil && c.max != nil && c.min.Cmp(c.max) > 0 {
+			return nil, fmt.Errorf("has bad constraint '%s': min exceeds max", source)
+		}
+
+		constraints = append(constraints, c)
+	}
+
+	return constraints, nil
+}
+
+func parseBound(str string) (*big.Rat, error) {
+	if str == "" {
+		return nil, nil
+	}
+
+	bound, ok := new(big.Rat).SetString(str)
+	if !ok {
+		return nil, fmt.Errorf("'%s' is not a number", str)
+	}
+
+	return bound, nil
+}
+
+// Reports whether 'text' captured for a verb satisfies the constraint.
+func (c constraint) satisfiedBy(text string) bool {
+	value, ok := new(big.Rat), true
+	if c.length {
+		value.SetInt64(int64(utf8.RuneCountInString(text)))
+	} else {
+		value, ok = value.SetString(text)
+	}
+
+	return ok &&
+		(c.min == nil || value.Cmp(c.min) >= 0) &&
+		(c.max == nil || value.Cmp(c.max) <= 0)
+}
+
+func (v verb) checkConstraints(text string) error {
+	// A repeated verb's values are checked one by one as they're assigned.
+	if v.repeat {
+		return nil
+	}
+
+	for _, c := range v.constraints {
+		if !c.satisfiedBy(text) {
+			return &ConstraintError{
+				Verb:       v.String(),
+				Text:       text,
+				Constraint: c.source,
+			}
+		}
+	}
+
+	return nil
+}
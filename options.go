@@ -0,0 +1,266 @@
+package unfmt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Option configures a Scanner.
+type Option func(*options)
+
+type options struct {
+	boolWords   []boolWord
+	normalizers map[int]func(string) string
+	validators  map[int]func(interface{}) error
+
+	// Normalizers and validators by the names of the verbs they're registered for.
+	namedNormalizers map[string]func(string) string
+	namedValidators  map[string]func(interface{}) error
+
+	defaults    map[int]string
+	nullTokens  []string
+	customVerbs []namedCustomVerb
+	structType  reflect.Type
+	structGiven bool
+}
+
+type namedCustomVerb struct {
+	name string
+	CustomVerb
+}
+
+/*
+WithBoolWords configures the '%t' verb to accept the given words for true and false
+instead of the values accepted by strconv.ParseBool, e.g. 'yes' and 'no' or 'on' and
+'off'. Words are matched case-insensitively and only where followed by a word boundary.
+*/
+func WithBoolWords(trueWords, falseWords []string) Option {
+	return func(o *options) {
+		o.boolWords = nil
+
+		for _, word := range trueWords {
+			o.boolWords = append(o.boolWords, boolWord{word: word, value: true})
+		}
+
+		for _, word := range falseWords {
+			o.boolWords = append(o.boolWords, boolWord{word: word, value: false})
+		}
+	}
+}
+
+/*
+WithNormalizer registers 'normalize' to transform the text captured for the verb at
+'index' in the format, e.g. with strings.ToLower or strings.TrimSpace, before it's
+converted and assigned. The verb then captures all of the text it would otherwise
+evaluate, up to the next space, its max width or the next segment of the format,
+rather than stopping where its value ends.
+*/
+func WithNormalizer(index int, normalize func(string) string) Option {
+	return func(o *options) {
+		if o.normalizers == nil {
+			o.normalizers = make(map[int]func(string) string)
+		}
+
+		o.normalizers[index] = normalize
+	}
+}
+
+// WithNormalizerFor registers 'normalize' as does WithNormalizer, but for the verb named 'name', e.g. 'user' for '%{user:s}'.
+func WithNormalizerFor(name string, normalize func(string) string) Option {
+	return func(o *options) {
+		if o.namedNormalizers == nil {
+			o.namedNormalizers = make(map[string]func(string) string)
+		}
+
+		o.namedNormalizers[name] = normalize
+	}
+}
+
+/*
+WithValidator registers 'validate' to check the value assigned for the verb at 'index'
+in the format, which it receives dereferenced from its target, e.g. an int for an *int
+target. Any error it returns is wrapped to identify the capture.
+*/
+func WithValidator(index int, validate func(interface{}) error) Option {
+	return func(o *options) {
+		if o.validators == nil {
+			o.validators = make(map[int]func(interface{}) error)
+		}
+
+		o.validators[index] = validate
+	}
+}
+
+// WithValidatorFor registers 'validate' as does WithValidator, but for the verb named 'name', e.g. 'port' for '%{port:d}'.
+func WithValidatorFor(name string, validate func(interface{}) error) Option {
+	return func(o *options) {
+		if o.namedValidators == nil {
+			o.namedValidators = make(map[string]func(interface{}) error)
+		}
+
+		o.namedValidators[name] = validate
+	}
+}
+
+/*
+WithDefault registers 'value' to be assigned for the verb at 'index' in the format
+whenever its capture is empty, as for a blank column, instead of failing. It's
+equivalent to declaring the default in long form, e.g. '%{d=0}', which it overrides.
+*/
+func WithDefault(index int, value string) Option {
+	return func(o *options) {
+		if o.defaults == nil {
+			o.defaults = make(map[int]string)
+		}
+
+		o.defaults[index] = value
+	}
+}
+
+/*
+WithNullTokens declares 'tokens' that stand for a missing value, e.g. '-' or 'N/A'.
+When one of them makes up the entire text a verb evaluates, the verb's target is set
+to its zero value, or to nil for a pointer-to-pointer target such as '**int', rather
+than converted.
+*/
+func WithNullTokens(tokens ...string) Option {
+	return func(o *options) {
+		o.nullTokens = append(o.nullTokens, tokens...)
+	}
+}
+
+// WithVerb makes a custom verb available under 'name' to a single Scanner. See VerbSet.Register for naming rules.
+func WithVerb(name string, cv CustomVerb) Option {
+	return func(o *options) {
+		o.customVerbs = append(o.customVerbs, namedCustomVerb{name: name, CustomVerb: cv})
+	}
+}
+
+// WithVerbs makes the custom verbs in each of 'sets' available to a Scanner. Names must not collide between them.
+func WithVerbs(sets ...*VerbSet) Option {
+	return func(o *options) {
+		for _, vs := range sets {
+			vs.each(func(name string, cv CustomVerb) {
+				o.customVerbs = append(o.customVerbs, namedCustomVerb{name: name, CustomVerb: cv})
+			})
+		}
+	}
+}
+
+/*
+WithStruct declares the struct type the Scanner's ScanStruct method assigns to,
+given a value or pointer of that type, e.g. 'Record{}' or '(*Record)(nil)'. The
+Scanner then checks when initialized that each of its verbs names a field of a
+type it can assign to, rather than on each scan.
+*/
+func WithStruct(v interface{}) Option {
+	return func(o *options) {
+		t := reflect.TypeOf(v)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		o.structType = t
+		o.structGiven = true
+	}
+}
+
+func newOptions(opts []Option) (options, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	err := o.validate()
+	return o, err
+}
+
+func (o options) validate() error {
+	if o.structGiven && (o.structType == nil || o.structType.Kind() != reflect.Struct) {
+		return fmt.Errorf("%w: WithStruct requires a struct or struct pointer, got %v", ErrBadArg, o.structType)
+	}
+
+	for i, cv := range o.customVerbs {
+		err := checkCustomVerb(cv.name, cv.CustomVerb)
+		if err != nil {
+			return err
+		}
+
+		for _, other := range o.customVerbs[:i] {
+			if cv.name == other.name {
+				return fmt.Errorf("%w: custom verb '%s' given more than once", ErrBadArg, cv.name)
+			}
+		}
+	}
+
+	for _, token := range o.nullTokens {
+		if token == "" {
+			return fmt.Errorf("%w: null tokens must not be empty", ErrBadArg)
+		}
+	}
+
+	for i, bw := range o.boolWords {
+		if bw.word == "" {
+			return fmt.Errorf("%w: bool words must not be empty", ErrBadArg)
+		}
+
+		for _, other := range o.boolWords[:i] {
+			if strings.EqualFold(bw.word, other.word) && bw.value != other.value {
+				return fmt.Errorf("%w: bool word '%s' given for both true and false", ErrBadArg, bw.word)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns the assign funcs for a pattern's verbs, with any overrides per the options.
+func (o options) assignFuncs() map[string]assignFunc {
+	funcs := make(map[string]assignFunc, len(assignFuncs))
+	for value, f := range assignFuncs {
+		funcs[value] = f
+	}
+
+	if len(o.boolWords) > 0 {
+		funcs[verbBool] = newBoolWordsAssignFunc(o.boolWords)
+	}
+
+	for _, cv := range o.customVerbs {
+		funcs[cv.name] = cv.assignFunc()
+	}
+
+	return funcs
+}
+
+// Returns the funcs allocating values of the types a pattern's verbs most naturally assign to.
+func (o options) newTargetFuncs() map[string]func() interface{} {
+	funcs := make(map[string]func() interface{}, len(newTargetFuncs))
+	for value, f := range newTargetFuncs {
+		funcs[value] = f
+	}
+
+	for _, cv := range o.customVerbs {
+		funcs[cv.name] = cv.New
+	}
+
+	return funcs
+}
+
+// Returns the render funcs for a pattern's verbs, with any overrides per the options.
+func (o options) renderFuncs() map[string]renderFunc {
+	funcs := make(map[string]renderFunc, len(renderFuncs))
+	for value, f := range renderFuncs {
+		funcs[value] = f
+	}
+
+	if len(o.boolWords) > 0 {
+		funcs[verbBool] = newBoolWordsRenderFunc(o.boolWords)
+	}
+
+	for _, cv := range o.customVerbs {
+		funcs[cv.name] = cv.renderFunc()
+	}
+
+	return funcs
+}
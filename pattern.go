@@ -2,8 +2,11 @@ package unfmt
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 const flagRunes runes = "#-. 0123456789"
@@ -20,6 +23,14 @@ func (rns runes) includes(r rune) bool {
 
 type pattern struct {
 	format            string
+	assignFuncs       map[string]assignFunc
+	newTargetFuncs    map[string]func() interface{}
+	renderFuncs       map[string]renderFunc
+	normalizers       map[int]func(string) string
+	validators        map[int]func(interface{}) error
+	nullTokens        []string
+	structType        reflect.Type
+	structFields      []structField
 	verbs             []verb
 	segments          []segment
 	trueSegmentStarts []int
@@ -31,23 +42,67 @@ type captureGroup struct {
 	verbs  []verb
 }
 
+type capture struct {
+	verb verb
+	text string
+
+	// Whether the text is one of the pattern's null tokens, and so wasn't converted.
+	null bool
+}
+
 type segment struct {
 	value       string
 	formatStart int
 	starts      []int
 }
 
-func newPattern(format string) (p pattern, err error) {
+func newPattern(format string, opts options) (p pattern, err error) {
+	p.assignFuncs = opts.assignFuncs()
+	p.newTargetFuncs = opts.newTargetFuncs()
+	p.renderFuncs = opts.renderFuncs()
+	p.normalizers = make(map[int]func(string) string, len(opts.normalizers))
+	for i, normalize := range opts.normalizers {
+		p.normalizers[i] = normalize
+	}
+
+	p.validators = make(map[int]func(interface{}) error, len(opts.validators))
+	for i, validate := range opts.validators {
+		p.validators[i] = validate
+	}
+	p.nullTokens = opts.nullTokens
+
 	err = p.parseVerbs(format)
 	if err != nil {
 		return
 	}
 
+	err = p.checkHookIndexes()
+	if err != nil {
+		return
+	}
+
+	err = p.applyNamedHooks(opts.namedNormalizers, opts.namedValidators)
+	if err != nil {
+		return
+	}
+
+	err = p.applyDefaults(opts.defaults)
+	if err != nil {
+		return
+	}
+
+	if opts.structType != nil {
+		p.structType = opts.structType
+
+		p.structFields, err = p.bindStruct(opts.structType)
+		if err != nil {
+			return
+		}
+	}
+
 	p.format = format
 
-	// After parsing verbs, must unescape '%%'s before parsing segments
-	// in order to match literal '%'s in the string input.
-	err = p.parseSegments(unescapeFormat(format))
+	err = p.parseSegments(format)
 	return
 }
 
@@ -64,13 +119,27 @@ func unescapeFormat(format string) string {
 	return strings.ReplaceAll(format, "%%", "%")
 }
 
+/*
+Parses the verbs in 'format' and stores them on the pattern instance, each
+with the index of the target it assigns to. As in package fmt, an explicit
+argument index in brackets among a verb's flags, e.g. '%[2]d', sets the index,
+counting from 1, and any verbs after it take the indexes that follow.
+*/
 func (p *pattern) parseVerbs(format string) error {
 	var seekVerb bool
 	var flags []rune
+	var skipTo int
+	var verbStart, argIndex int
+	var explicitIndex bool
 
 	for idx, nextRune := range format {
+		if idx < skipTo {
+			continue
+		}
+
 		if !seekVerb {
 			seekVerb = nextRune == '%'
+			verbStart = idx
 			continue
 		}
 
@@ -79,25 +148,330 @@ func (p *pattern) parseVerbs(format string) error {
 			seekVerb = false
 		case flagRunes.includes(nextRune):
 			flags = append(flags, nextRune)
-		case isSupportedVerb(nextRune):
-			offset := len("%") + len(flags)
-			p.verbs = append(p.verbs, verb{
-				start: idx - offset,
-				value: nextRune,
-				flags: flags,
-			})
+		case nextRune == '[' && !explicitIndex:
+			n, err := parseArgIndex(format, idx)
+			if err != nil {
+				return err
+			}
 
+			argIndex, explicitIndex = n-1, true
+			skipTo = idx + strings.IndexByte(format[idx:], ']') + 1
+		case nextRune == '{':
+			v, err := p.parseLongFormVerb(format, verbStart, idx, flags)
+			if err != nil {
+				return err
+			}
+
+			v.start = verbStart
+			skipTo = v.start + v.len()
 			seekVerb = false
 
 			flags = nil
+
+			// A back-reference assigns to its earlier verb's target, leaving the next index as is.
+			if v.backRef {
+				if explicitIndex {
+					return fmt.Errorf("%w: at column %d: back-reference '%s' takes no argument index", ErrBadArg, verbStart+1, v)
+				}
+
+				v.argIndex = p.verbs[v.ref].argIndex
+				p.verbs = append(p.verbs, v)
+				continue
+			}
+
+			v.argIndex = argIndex
+			p.verbs = append(p.verbs, v)
+
+			argIndex, explicitIndex = argIndex+1, false
+		case p.isSupportedVerb(string(nextRune)):
+			v := verb{
+				start:    verbStart,
+				value:    string(nextRune),
+				flags:    flags,
+				argIndex: argIndex,
+			}
+
+			if explicitIndex {
+				v.source = format[verbStart : idx+utf8.RuneLen(nextRune)]
+			}
+
+			p.verbs = append(p.verbs, v)
+
+			seekVerb = false
+
+			flags = nil
+			argIndex, explicitIndex = argIndex+1, false
 		default:
 			return fmt.Errorf("%w: unsupported verb '%s'", ErrBadArg, verb{
-				value: nextRune,
+				value: string(nextRune),
 				flags: flags,
 			})
 		}
 	}
 
+	return p.checkArgIndexes()
+}
+
+// Parses the explicit argument index in brackets at index 'open' of 'format', e.g. '[2]'.
+func parseArgIndex(format string, open int) (int, error) {
+	end := strings.IndexByte(format[open:], ']')
+	if end < 0 {
+		return 0, fmt.Errorf("%w: at column %d: missing ']' to close argument index '%s'", ErrBadArg, open+1, format[open:])
+	}
+
+	digits := format[open+1 : open+end]
+
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 1 || runSpan(digits, digitRunes) < len(digits) {
+		return 0, fmt.Errorf("%w: at column %d: bad argument index '%s'; must be a number from 1", ErrBadArg, open+1, format[open:open+end+1])
+	}
+
+	return n, nil
+}
+
+// Returns ErrBadArg if any target index up to the highest of the pattern's verbs isn't assigned to by some verb.
+func (p pattern) checkArgIndexes() error {
+	used := make([]bool, p.targetCount())
+	for _, v := range p.verbs {
+		used[v.argIndex] = true
+	}
+
+	for i, ok := range used {
+		if !ok {
+			return fmt.Errorf("%w: argument index [%d] is used by no verb", ErrBadArg, i+1)
+		}
+	}
+
+	return nil
+}
+
+/*
+Parses a verb in long form from 'format' at index 'start', where it begins
+with '%' and any flags, and index 'open' of its '{':
+
+	%[flags]{[name:]type[=default][:options]}
+
+e.g. '%{user:s}', '%{port:d:1..65535}', '%.2{m}' or '%{ts:time:2006-01-02}'.
+The options are interpreted per type: '|'-separated values for 'enum', a layout
+for 'time' and constraints for the rest. A first part that is itself a type,
+or isn't followed by one, is taken as the type, so options may contain ':'s
+unescaped, as in '%{time:15:04:05}', and begin with a type, as in '%{enum:s|m|l}'.
+
+Within the braces, '\' escapes the next character so that it's taken literally,
+e.g. '\}', '\:' or '\\'. A '%' needs no escaping.
+
+Errors report the column in 'format', counting from 1, at which the problem lies.
+*/
+func (p *pattern) parseLongFormVerb(format string, start, open int, flags []rune) (verb, error) {
+	bodyStart := open + len("{")
+
+	bodyLen := indexUnescaped(format[bodyStart:], '}')
+	if bodyLen < 0 {
+		return verb{}, fmt.Errorf("%w: at column %d: missing '}' to close verb '%s'", ErrBadArg, start+1, format[start:])
+	}
+
+	end := bodyStart + bodyLen
+	v := verb{
+		flags:  flags,
+		source: format[start : end+1],
+	}
+
+	if strings.HasPrefix(format[bodyStart:end], "=") {
+		return p.parseBackRef(v, format, bodyStart, end)
+	}
+
+	// Index 'format' by the parts of the body, each ending at an unescaped ':' or the end.
+	typeStart, typeEnd := bodyStart, end
+	optionsStart := -1
+	if i := indexUnescaped(format[bodyStart:end], ':'); i >= 0 {
+		typeEnd, optionsStart = bodyStart+i, bodyStart+i+1
+
+		secondEnd := end
+		if j := indexUnescaped(format[optionsStart:end], ':'); j >= 0 {
+			secondEnd = optionsStart + j
+		}
+
+		first, _, _ := splitDefault(format[bodyStart:typeEnd])
+		second, _, _ := splitDefault(format[optionsStart:secondEnd])
+
+		if !p.isSupportedVerb(first) && p.isSupportedVerb(repeatedType(second)) {
+			v.name = unescapeLongForm(format[bodyStart:typeEnd])
+			if !isName(v.name) {
+				return verb{}, fmt.Errorf("%w: at column %d: verb '%s' has bad name '%s'", ErrBadArg, bodyStart+1, v, v.name)
+			}
+
+			for _, other := range p.verbs {
+				if other.name == v.name {
+					return verb{}, fmt.Errorf("%w: at column %d: verb '%s' has duplicate name '%s'", ErrBadArg, bodyStart+1, v, v.name)
+				}
+			}
+
+			typeStart, typeEnd = optionsStart, secondEnd
+
+			optionsStart = -1
+			if secondEnd < end {
+				optionsStart = secondEnd + 1
+			}
+		}
+	}
+
+	v.value, v.defaultValue, v.hasDefault = splitDefault(format[typeStart:typeEnd])
+
+	var repetition string
+	v.value, repetition = splitRepeat(v.value)
+
+	if !p.isSupportedVerb(v.value) {
+		return verb{}, fmt.Errorf("%w: at column %d: verb '%s' has unsupported type '%s'", ErrBadArg, typeStart+1, v, v.value)
+	}
+
+	if repetition != "" {
+		err := v.parseRepetition(repetition)
+		if err != nil {
+			return verb{}, fmt.Errorf("%w: at column %d: verb '%s' %s", ErrBadArg, typeStart+len(v.value)+1, v, err)
+		}
+
+		// With no min count, no values at all is a valid capture.
+		if v.minCount == 0 {
+			v.hasDefault = true
+		}
+	}
+
+	if optionsStart >= 0 {
+		v.options = format[optionsStart:end]
+	}
+
+	err := v.parseOptions()
+	if err != nil {
+		column := typeStart + 1
+		if optionsStart >= 0 {
+			column = optionsStart + 1
+		}
+
+		return verb{}, fmt.Errorf("%w: at column %d: verb '%s' %s", ErrBadArg, column, v, err)
+	}
+
+	if v.hasDefault {
+		err = p.checkDefault(v)
+		if err != nil {
+			column := typeStart + len(v.value) + len("=") + 1
+			return verb{}, fmt.Errorf("%w: at column %d: %s", ErrBadArg, column, err)
+		}
+	}
+
+	return v, nil
+}
+
+/*
+Parses a back-reference, '%{=name}' or '%{=n}', to the earlier verb with that
+name or argument index, which 'v' then copies. The back-reference captures
+only text identical to the earlier verb's capture, and assigns to the same target.
+*/
+func (p pattern) parseBackRef(v verb, format string, bodyStart, end int) (verb, error) {
+	ref := unescapeLongForm(format[bodyStart+len("=") : end])
+
+	v.ref = -1
+	for i, other := range p.verbs {
+		if other.name != "" && other.name == ref || strconv.Itoa(other.argIndex+1) == ref {
+			v.ref = i
+			break
+		}
+	}
+
+	if v.ref < 0 {
+		return verb{}, fmt.Errorf("%w: at column %d: verb '%s' references no earlier verb '%s'", ErrBadArg, bodyStart+2, v, ref)
+	}
+
+	v.value = p.verbs[v.ref].value
+	v.options = p.verbs[v.ref].options
+	v.backRef = true
+
+	return v, nil
+}
+
+// Returns the type of a long-form verb's value, less any repetition.
+func repeatedType(value string) string {
+	typ, _ := splitRepeat(value)
+	return typ
+}
+
+// Splits the type part of a long-form verb's body into its type and any default value, unescaped.
+func splitDefault(part string) (value, defaultValue string, hasDefault bool) {
+	i := indexUnescaped(part, '=')
+	if i < 0 {
+		return unescapeLongForm(part), "", false
+	}
+
+	return unescapeLongForm(part[:i]), unescapeLongForm(part[i+1:]), true
+}
+
+// Reports whether 'str' can name a verb: a letter or '_', followed by any letters, digits or '_'s.
+func isName(str string) bool {
+	for i, r := range str {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+
+	return str != ""
+}
+
+// Returns the index of the first 'c' in 'str' not escaped by a '\', or -1 if none.
+func indexUnescaped(str string, c byte) int {
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Splits 'str' around each 'sep' not escaped by a '\', unescaping the substrings.
+func splitUnescaped(str string, sep byte) []string {
+	var parts []string
+	for {
+		i := indexUnescaped(str, sep)
+		if i < 0 {
+			return append(parts, unescapeLongForm(str))
+		}
+
+		parts = append(parts, unescapeLongForm(str[:i]))
+		str = str[i+1:]
+	}
+}
+
+// Replaces each character escaped by a '\' in the body of a long-form verb with the character itself.
+func unescapeLongForm(str string) string {
+	if strings.IndexByte(str, '\\') < 0 {
+		return str
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(str); i++ {
+		if str[i] == '\\' && i+1 < len(str) {
+			i++
+		}
+
+		b.WriteByte(str[i])
+	}
+
+	return b.String()
+}
+
+// Reports an error if the verb's default value can't be assigned in full to the type it most naturally assigns to.
+func (p pattern) checkDefault(v verb) error {
+	n, err := p.assignTo(v.defaultValue, p.newTarget(v), v)
+	if err == nil && n < len(v.defaultValue) {
+		err = fmt.Errorf("only '%s' is valid", v.defaultValue[:n])
+	}
+
+	if err != nil {
+		return fmt.Errorf("bad default '%s' for verb '%s': %s", v.defaultValue, v, err)
+	}
+
 	return nil
 }
 
@@ -116,21 +490,20 @@ also for 3 verbs.
 
 Format '%5s%d' yields 0 segments for 2 verbs.
 
-Expects any literal '%'s in the format to be unescaped.
+Unescapes any '%%'s in the segments in order to match literal '%'s
+in the string input, while each segment's start remains its index in
+the format as written.
 */
-func (p *pattern) parseSegments(unescapedFormat string) error {
+func (p *pattern) parseSegments(format string) error {
 	maxSegments := len(p.verbs) + 1
 	p.segments = make([]segment, 0, maxSegments)
 
-	remainder := unescapedFormat
 	index := 0
 
 	for i, verb := range p.verbs {
-		verbIndex := strings.Index(remainder, verb.String())
-
-		if len(remainder[:verbIndex]) > 0 {
+		if verb.start > index {
 			p.segments = append(p.segments, segment{
-				value:       remainder[:verbIndex],
+				value:       unescapeFormat(format[index:verb.start]),
 				formatStart: index,
 			})
 		} else if i > 0 {
@@ -138,7 +511,7 @@ func (p *pattern) parseSegments(unescapedFormat string) error {
 			if verb.value == previousVerb.value {
 				if _, ok := previousVerb.maxWidth(); !ok {
 					return fmt.Errorf(
-						"%w: found consecutive instances of verb '%%%c' without a max width or intervening substring",
+						"%w: found consecutive instances of verb '%%%s' without a max width or intervening substring",
 						ErrBadArg,
 						verb.value,
 					)
@@ -146,14 +519,12 @@ func (p *pattern) parseSegments(unescapedFormat string) error {
 			}
 		}
 
-		index += verbIndex + verb.len()
-
-		remainder = remainder[verbIndex+verb.len():]
+		index = verb.start + verb.len()
 	}
 
-	if len(remainder) > 0 {
+	if index < len(format) {
 		p.segments = append(p.segments, segment{
-			value:       remainder,
+			value:       unescapeFormat(format[index:]),
 			formatStart: index,
 		})
 	}
@@ -161,19 +532,23 @@ func (p *pattern) parseSegments(unescapedFormat string) error {
 	return nil
 }
 
+/*
+Captures the text for each verb from 'str', checking any candidate sets of segment
+starts against new values of the types 'targetPtrs' point to.
+*/
 // TODO: Update me to take any other capture-limiting flags into account besides max width.
-func (p *pattern) capture(str string) error {
+func (p *pattern) capture(str string, targetPtrs []interface{}) error {
 	err := p.findAllSegmentStarts(str)
 	if err != nil {
 		return err
 	}
 
-	err = p.getTrueSegmentStarts()
+	err = p.getTrueSegmentStarts(str, targetPtrs)
 	if err != nil {
 		return err
 	}
 
-	err = p.getCaptureGroups(str)
+	p.captureGroups, err = p.getCaptureGroups(str, p.trueSegmentStarts)
 	if err != nil {
 		return err
 	}
@@ -219,13 +594,18 @@ not match the pattern.
 
 Returns ErrMultipleMatches if the string input contains more than one set
 of segments perfectly matching the pattern, making the intended captures
-ambiguous.
+ambiguous. Before that, any set whose captures can't be assigned to the verbs
+between its segments, violate the verbs' constraints or differ where they must
+be equal, as for back-references, is discarded. If that leaves no set, returns
+the error from the first.
 */
-func (p *pattern) getTrueSegmentStarts() error {
+func (p *pattern) getTrueSegmentStarts(str string, targetPtrs []interface{}) error {
 	if len(p.segments) == 0 {
 		return nil
 	}
 
+	var candidates [][]int
+
 	lastSegmentStarts := p.segments[len(p.segments)-1].starts
 
 	// Each start index found for the last segment in the pattern begins
@@ -243,10 +623,15 @@ func (p *pattern) getTrueSegmentStarts() error {
 		for i := len(p.segments) - 2; i >= 0; i-- {
 			nextSegmentBack := p.segments[i]
 
+			// Segments may be adjacent in the string input only if the verbs
+			// between them in the format have defaults to assign instead.
+			adjacentOK := allHaveDefaults(p.verbsBetween(i, i+1))
+
 			for j := len(nextSegmentBack.starts) - 1; j >= 0; j-- {
 				earliestSegmentStart := starts[0]
+				segmentEnd := nextSegmentBack.starts[j] + len(nextSegmentBack.value)
 
-				if (nextSegmentBack.starts[j] + len(nextSegmentBack.value)) < earliestSegmentStart {
+				if segmentEnd < earliestSegmentStart || segmentEnd == earliestSegmentStart && adjacentOK {
 					// Since we're working backwards from last to first segment,
 					// prepend each next found start to the slice to keep it sorted.
 					starts = append(starts, 0)
@@ -258,31 +643,83 @@ func (p *pattern) getTrueSegmentStarts() error {
 		}
 
 		if len(starts) == len(p.segments) {
-			if len(p.trueSegmentStarts) > 0 {
-				return ErrMultipleMatches
-			}
-
-			p.trueSegmentStarts = starts
+			candidates = append(candidates, starts)
 		}
 	}
 
-	if len(p.trueSegmentStarts) < 1 {
+	if len(candidates) < 1 {
 		return ErrNoMatch
 	}
 
+	if len(candidates) > 1 {
+		var firstErr error
+
+		valid := candidates[:0]
+		for _, starts := range candidates {
+			err := p.tryCandidate(str, starts, targetPtrs)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+
+				continue
+			}
+
+			valid = append(valid, starts)
+		}
+
+		if len(valid) < 1 {
+			return firstErr
+		}
+
+		if len(valid) > 1 {
+			return ErrMultipleMatches
+		}
+
+		candidates = valid
+	}
+
+	p.trueSegmentStarts = candidates[0]
+
 	return nil
 }
 
+/*
+Reports any error capturing, converting or equality-checking values from 'str' given
+a candidate set of segment starts. Values are converted to new values of the types
+'targetPtrs' point to, leaving the targets themselves untouched.
+*/
+func (p pattern) tryCandidate(str string, starts []int, targetPtrs []interface{}) error {
+	groups, err := p.getCaptureGroups(str, starts)
+	if err != nil {
+		return err
+	}
+
+	captures, err := p.splitCaptureGroups(groups, func(i int, v verb) (interface{}, error) {
+		if len(targetPtrs) <= i {
+			return nil, fmt.Errorf("%w: no element found at 'targetPtrs[%d]' for next verb '%s'", ErrBug, i, v)
+		}
+
+		return newLike(targetPtrs[i]), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return checkRepeatedCaptures(captures)
+}
+
 // TODO: Split into more readable parts? Or at least comment.
-func (p *pattern) getCaptureGroups(str string) error {
+func (p pattern) getCaptureGroups(str string, starts []int) ([]captureGroup, error) {
 	segments := p.segments
-	starts := p.trueSegmentStarts
+
+	var groups []captureGroup
 
 	// If no segment starts, then the format consists only of
 	// one or more verbs, against which the whole string should
 	// be evaluated.
 	if len(starts) == 0 {
-		p.captureGroups = append(p.captureGroups, captureGroup{
+		groups = append(groups, captureGroup{
 			substr: str,
 			verbs:  p.verbs,
 		})
@@ -309,14 +746,14 @@ func (p *pattern) getCaptureGroups(str string) error {
 			}
 
 			substr := str[:start]
-			if len(substr) == 0 {
-				return fmt.Errorf(
+			if len(substr) == 0 && !allHaveDefaults(p.verbs[from:to]) {
+				return nil, fmt.Errorf(
 					"%w: expected capture at start of 'str' for leading verb(s)",
 					ErrEmptyCapture,
 				)
 			}
 
-			p.captureGroups = append(p.captureGroups, captureGroup{
+			groups = append(groups, captureGroup{
 				substr: str[:start],
 				verbs:  p.verbs[from:to],
 			})
@@ -341,14 +778,14 @@ func (p *pattern) getCaptureGroups(str string) error {
 
 				captureFrom := start + len(segments[i].value)
 				substr := str[captureFrom:]
-				if len(substr) == 0 {
-					return fmt.Errorf(
+				if len(substr) == 0 && !allHaveDefaults(p.verbs[from:to]) {
+					return nil, fmt.Errorf(
 						"%w: expected capture at end of 'str' for final verb(s)",
 						ErrEmptyCapture,
 					)
 				}
 
-				p.captureGroups = append(p.captureGroups, captureGroup{
+				groups = append(groups, captureGroup{
 					substr: str[captureFrom:],
 					verbs:  p.verbs[from:to],
 				})
@@ -375,8 +812,8 @@ func (p *pattern) getCaptureGroups(str string) error {
 		captureFrom := start + len(segments[i].value)
 		captureTo := starts[i+1]
 		substr := str[captureFrom:captureTo]
-		if len(substr) == 0 {
-			return fmt.Errorf(
+		if len(substr) == 0 && !allHaveDefaults(p.verbs[from:to]) {
+			return nil, fmt.Errorf(
 				"%w: no string to capture between matching segments '%s' and '%s', so pattern should not have matched",
 				ErrBug,
 				segment.value,
@@ -384,42 +821,109 @@ func (p *pattern) getCaptureGroups(str string) error {
 			)
 		}
 
-		p.captureGroups = append(p.captureGroups, captureGroup{
+		groups = append(groups, captureGroup{
 			substr: str[captureFrom:captureTo],
 			verbs:  p.verbs[from:to],
 		})
 	}
 
-	for _, group := range p.captureGroups {
+	for _, group := range groups {
 		if len(group.verbs) == 0 {
-			return fmt.Errorf("%w: no verbs assigned to captured substring '%s'", ErrBug, group.substr)
+			return nil, fmt.Errorf("%w: no verbs assigned to captured substring '%s'", ErrBug, group.substr)
+		}
+	}
+
+	return groups, nil
+}
+
+// Assigns the pattern's captures to 'targetPtrs', returning the text assigned for each verb.
+func (p pattern) assign(targetPtrs []interface{}) ([]capture, error) {
+	captures, err := p.splitCaptureGroups(p.captureGroups, func(i int, v verb) (interface{}, error) {
+		if len(targetPtrs) <= i {
+			return nil, fmt.Errorf("%w: no element found at 'targetPtrs[%d]' for next verb '%s'", ErrBug, i, v)
+		}
+
+		return targetPtrs[i], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkRepeatedCaptures(captures)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, c := range captures {
+		validate, ok := p.validators[i]
+		if !ok || c.null {
+			continue
+		}
+
+		err = validate(reflect.ValueOf(targetPtrs[c.verb.argIndex]).Elem().Interface())
+		if err != nil {
+			return nil, fmt.Errorf("at index %d: validating '%s' captured for verb '%s': %w", c.verb.argIndex, c.text, c.verb, err)
+		}
+	}
+
+	return captures, nil
+}
+
+// Returns an error if any verbs sharing an argument index, as in '%[1]s-%[1]s', captured different text.
+func checkRepeatedCaptures(captures []capture) error {
+	firsts := make(map[int]capture)
+	for _, c := range captures {
+		first, ok := firsts[c.verb.argIndex]
+		if !ok {
+			firsts[c.verb.argIndex] = c
+			continue
+		}
+
+		if c.text != first.text {
+			return fmt.Errorf(
+				"at index %d: '%s' captured for verb '%s' differs from '%s' captured for verb '%s'",
+				c.verb.argIndex,
+				c.text,
+				c.verb,
+				first.text,
+				first.verb,
+			)
 		}
 	}
 
 	return nil
 }
 
-func (p pattern) assign(targetPtrs []interface{}) error {
-	targetPtrsIndex := 0
-	for _, group := range p.captureGroups {
+/*
+Splits the substring of each capture group among the group's verbs in order,
+assigning each verb's share to the target returned by 'targetFor' for the verb's
+argument index and checking it against any of the verb's constraints.
+
+A verb with a normalizer takes all of the substring it would otherwise evaluate,
+i.e. up to the next space or its max width, and is assigned the normalized text.
+
+Returns the text assigned for each verb.
+*/
+func (p pattern) splitCaptureGroups(groups []captureGroup, targetFor func(int, verb) (interface{}, error)) ([]capture, error) {
+	var captures []capture
+
+	var targetPtrsIndex int
+	verbIndex := 0
+	for _, group := range groups {
 
 		var err error
 		substr := group.substr
 
 		for _, verb := range group.verbs {
-			if len(targetPtrs) <= targetPtrsIndex {
-				err = fmt.Errorf(
-					"%w: no element found at 'targetPtrs[%d]' for next verb '%s' and substring '%s'",
-					ErrBug,
-					targetPtrsIndex,
-					verb,
-					substr,
-				)
+			targetPtrsIndex = verb.argIndex
 
+			var target interface{}
+			target, err = targetFor(targetPtrsIndex, verb)
+			if err != nil {
 				break
 			}
 
-			if len(substr) == 0 {
+			if len(substr) == 0 && !verb.hasDefault {
 				err = fmt.Errorf(
 					"all of substring '%s' consumed by prior adjacent verb(s), none left for next verb '%s'",
 					group.substr,
@@ -431,24 +935,80 @@ func (p pattern) assign(targetPtrs []interface{}) error {
 
 			substr = strings.TrimLeftFunc(substr, unicode.IsSpace)
 
-			// For this next value to be assigned, evaluate the full remaining substring with two
-			// exceptions. If it contains a space character, stop evaluation there. And if this verb
-			// specifies a max width less than the length of the remaining substring or less than the
-			// index of the next space character, only take that much of the substring.
-			stopEvaluateIndex := len(substr)
+			// A back-reference captures as its earlier verb would, but only text identical to the earlier
+			// capture, whose target already holds its value. So it converts into a new value instead.
+			if verb.backRef {
+				ref := captures[verb.ref]
+
+				text := substr[:evaluationEnd(substr, ref.verb)]
+				if !p.isNullToken(text) {
+					var n int
+					n, err = p.assignTo(text, newLike(target), ref.verb)
+					if err != nil {
+						break
+					}
 
-			nextSpaceIndex := strings.IndexFunc(substr, unicode.IsSpace)
-			if nextSpaceIndex >= 0 && verb.stopAtSpaces() {
-				stopEvaluateIndex = nextSpaceIndex
+					text = text[:n]
+				}
+
+				if text != ref.text {
+					err = fmt.Errorf("expected '%s' captured for verb '%s' at start of '%s' for back-reference '%s'", ref.text, ref.verb, substr, verb)
+					break
+				}
+
+				captures = append(captures, capture{verb: verb, text: ref.text, null: ref.null})
+
+				substr = substr[len(text):]
+
+				verbIndex++
+				continue
 			}
-			if maxWidth, ok := verb.maxWidth(); ok && maxWidth < stopEvaluateIndex {
-				stopEvaluateIndex = maxWidth
+
+			if len(substr) == 0 && verb.hasDefault {
+				_, err = p.assignTo(verb.defaultValue, target, verb)
+				if err != nil {
+					break
+				}
+
+				captures = append(captures, capture{verb: verb, text: verb.defaultValue})
+
+				verbIndex++
+				continue
 			}
 
-			assignFunc := assignFuncs[verb.value]
+			stopEvaluateIndex := evaluationEnd(substr, verb)
+
+			text := substr[:stopEvaluateIndex]
+
+			if p.isNullToken(text) {
+				if target != nil {
+					err = setZero(target)
+					if err != nil {
+						break
+					}
+				}
+
+				captures = append(captures, capture{verb: verb, text: text, null: true})
+
+				substr = substr[stopEvaluateIndex:]
+
+				verbIndex++
+				continue
+			}
+
+			normalize, normalizing := p.normalizers[verbIndex]
+			if normalizing {
+				text = normalize(text)
+			}
+
+			// Convert into a new value first, so that the target is only set once constraints are satisfied.
+			converted := newLike(target)
+			if converted == nil {
+				converted = target
+			}
 
 			var n int
-			n, err = assignFunc(substr[:stopEvaluateIndex], targetPtrs[targetPtrsIndex])
+			n, err = p.assignTo(text, converted, verb)
 			if err != nil {
 				break
 			}
@@ -456,23 +1016,293 @@ func (p pattern) assign(targetPtrs []interface{}) error {
 			// Before the next verb, re-slice the substring to start wherever evaluation
 			// stopped for this latest assignment, which may be less than the index above
 			// if fewer bytes of that string were actually evaluated than those passed.
-			if n < stopEvaluateIndex {
+			if !normalizing && n < stopEvaluateIndex {
 				stopEvaluateIndex = n
+				text = text[:n]
 			}
 
+			err = verb.checkConstraints(text)
+			if err != nil {
+				break
+			}
+
+			if converted != target {
+				reflect.ValueOf(target).Elem().Set(reflect.ValueOf(converted).Elem())
+			}
+
+			captures = append(captures, capture{verb: verb, text: text})
+
 			substr = substr[stopEvaluateIndex:]
 
-			targetPtrsIndex++
+			verbIndex++
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("at index %d: %w", targetPtrsIndex, err)
+		}
+	}
+
+	return captures, nil
+}
+
+/*
+Returns the index in 'substr' at which evaluation stops for the next value to be
+assigned by 'v'. That's the end of the full remaining substring with two exceptions.
+If it contains a space character, stop evaluation there. And if this verb specifies
+a max width less than the length of the remaining substring or less than the index
+of the next space character, only take that much of the substring.
+*/
+func evaluationEnd(substr string, v verb) int {
+	end := len(substr)
+
+	nextSpaceIndex := strings.IndexFunc(substr, unicode.IsSpace)
+	if nextSpaceIndex >= 0 && v.stopAtSpaces() {
+		end = nextSpaceIndex
+	}
+	if maxWidth, ok := v.maxWidth(); ok && maxWidth < end {
+		end = maxWidth
+	}
+
+	return end
+}
+
+// Sets default values on verbs by index, overriding any declared in the format.
+func (p *pattern) applyDefaults(defaults map[int]string) error {
+	for i, defaultValue := range defaults {
+		if i < 0 || i >= len(p.verbs) {
+			return fmt.Errorf("%w: default registered for verb index %d of %d verbs", ErrBadArg, i, len(p.verbs))
 		}
 
+		p.verbs[i].defaultValue, p.verbs[i].hasDefault = defaultValue, true
+
+		err := p.checkDefault(p.verbs[i])
 		if err != nil {
-			return fmt.Errorf("at index %d: %w", targetPtrsIndex, err)
+			return fmt.Errorf("%w: %s", ErrBadArg, err)
 		}
 	}
 
 	return nil
 }
 
+// Returns the verbs between the segments at indexes 'from' and 'to' in the format.
+func (p pattern) verbsBetween(from, to int) []verb {
+	var verbs []verb
+	for _, v := range p.verbs {
+		if v.start > p.segments[from].formatStart && v.start < p.segments[to].formatStart {
+			verbs = append(verbs, v)
+		}
+	}
+
+	return verbs
+}
+
+func allHaveDefaults(verbs []verb) bool {
+	for _, v := range verbs {
+		if !v.hasDefault {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns ErrBadArg if any normalizer or validator is registered for a verb index not in the pattern.
+func (p pattern) checkHookIndexes() error {
+	for i := range p.normalizers {
+		if i < 0 || i >= len(p.verbs) {
+			return fmt.Errorf("%w: normalizer registered for verb index %d of %d verbs", ErrBadArg, i, len(p.verbs))
+		}
+	}
+
+	for i := range p.validators {
+		if i < 0 || i >= len(p.verbs) {
+			return fmt.Errorf("%w: validator registered for verb index %d of %d verbs", ErrBadArg, i, len(p.verbs))
+		}
+	}
+
+	return nil
+}
+
+/*
+Registers normalizers and validators given by verb name under the index of the verb
+with that name, overriding any registered for the index. Returns ErrBadArg if no verb
+in the pattern has the name.
+*/
+func (p *pattern) applyNamedHooks(normalizers map[string]func(string) string, validators map[string]func(interface{}) error) error {
+	for name, normalize := range normalizers {
+		i, ok := p.verbIndexByName(name)
+		if !ok {
+			return fmt.Errorf("%w: normalizer registered for verb name '%s' not in the pattern", ErrBadArg, name)
+		}
+
+		p.normalizers[i] = normalize
+	}
+
+	for name, validate := range validators {
+		i, ok := p.verbIndexByName(name)
+		if !ok {
+			return fmt.Errorf("%w: validator registered for verb name '%s' not in the pattern", ErrBadArg, name)
+		}
+
+		p.validators[i] = validate
+	}
+
+	return nil
+}
+
+// Returns the index of the verb named 'name' among the pattern's verbs.
+func (p pattern) verbIndexByName(name string) (int, bool) {
+	for i, v := range p.verbs {
+		if v.name != "" && v.name == name {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+/*
+Assigns the value of 'text' per the verb to 'target', returning how many bytes
+were evaluated. A pointer-to-pointer target, e.g. '**int', receives a pointer
+to a newly allocated value, as does each pointer in turn for deeper nesting.
+*/
+func (p pattern) assignTo(text string, target interface{}, v verb) (int, error) {
+	assignFunc := p.assignFuncs[v.value]
+
+	ptrPtr := reflect.ValueOf(target)
+	if ptrPtr.Kind() != reflect.Ptr || ptrPtr.IsNil() || ptrPtr.Elem().Kind() != reflect.Ptr {
+		if v.repeat {
+			return p.assignRepeated(text, target, v)
+		}
+
+		return assignFunc(text, target, v)
+	}
+
+	ptr := reflect.New(ptrPtr.Elem().Type().Elem())
+
+	n, err := p.assignTo(text, ptr.Interface(), v)
+	if err != nil {
+		return 0, err
+	}
+
+	ptrPtr.Elem().Set(ptr)
+	return n, nil
+}
+
+/*
+Returns a pointer to a new value of the type 'v' most naturally assigns to,
+or nil for a custom verb that doesn't say, in which case assigning to it only
+checks the verb's match.
+*/
+func (p pattern) newTarget(v verb) interface{} {
+	return newTargetBy(v, p.newTargetFuncs[v.value])
+}
+
+// Returns a pointer to the value 'newFunc' allocates for 'v', or to a slice of such values for a repeated verb.
+func newTargetBy(v verb, newFunc func() interface{}) interface{} {
+	if newFunc == nil {
+		return nil
+	}
+
+	if v.repeat {
+		return reflect.New(reflect.SliceOf(reflect.TypeOf(newFunc()).Elem())).Interface()
+	}
+
+	return newFunc()
+}
+
+/*
+Returns a pointer to a new value of the type 'ptr' points to, or nil if 'ptr' isn't
+a non-nil pointer, in which case assigning to it only checks the verb's match.
+*/
+func newLike(ptr interface{}) interface{} {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil
+	}
+
+	return reflect.New(rv.Type().Elem()).Interface()
+}
+
+/*
+Allocates a target of the type each argument index's first verb most naturally
+assigns to, or of the type the func in 'overrides' for the verb's type allocates.
+*/
+func (p pattern) newTargets(overrides map[string]func() interface{}) ([]interface{}, error) {
+	targets := make([]interface{}, p.targetCount())
+	for _, v := range p.verbs {
+		if targets[v.argIndex] != nil {
+			continue
+		}
+
+		newFunc, ok := overrides[v.value]
+		if !ok {
+			newFunc = p.newTargetFuncs[v.value]
+		}
+
+		targets[v.argIndex] = newTargetBy(v, newFunc)
+		if targets[v.argIndex] == nil {
+			return nil, fmt.Errorf("%w: verb '%s' has no type to allocate for its capture", ErrBadArg, v)
+		}
+	}
+
+	return targets, nil
+}
+
+// Returns the name for each argument index, from the first of its verbs with one.
+func (p pattern) targetNames() ([]string, error) {
+	names := make([]string, p.targetCount())
+	for _, v := range p.verbs {
+		if names[v.argIndex] == "" {
+			names[v.argIndex] = v.name
+		}
+	}
+
+	for _, v := range p.verbs {
+		if names[v.argIndex] == "" {
+			return nil, fmt.Errorf("%w: verb '%s' has no name to map its capture to", ErrBadArg, v)
+		}
+	}
+
+	return names, nil
+}
+
+// Returns the value each target points to, or nil where all of its captures were null tokens.
+func targetValues(targets []interface{}, captures []capture) []interface{} {
+	null := make([]bool, len(targets))
+	for i := range null {
+		null[i] = true
+	}
+
+	for _, c := range captures {
+		null[c.verb.argIndex] = null[c.verb.argIndex] && c.null
+	}
+
+	values := make([]interface{}, len(targets))
+	for i, target := range targets {
+		if !null[i] {
+			values[i] = reflect.ValueOf(target).Elem().Interface()
+		}
+	}
+
+	return values
+}
+
+func (p pattern) isNullToken(text string) bool {
+	for _, token := range p.nullTokens {
+		if text == token {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p pattern) isSupportedVerb(value string) bool {
+	_, ok := p.assignFuncs[value]
+	return ok
+}
+
 func (p pattern) beginsWithVerb() bool {
 	if len(p.verbs) > 0 {
 		firstVerb := p.verbs[0]
@@ -495,3 +1325,27 @@ func (p pattern) endsWithVerb() bool {
 func (p pattern) verbCount() int {
 	return len(p.verbs)
 }
+
+// Returns the number of targets the pattern assigns to, fewer than its verbs if any share an argument index.
+func (p pattern) targetCount() int {
+	var count int
+	for _, v := range p.verbs {
+		if v.argIndex+1 > count {
+			count = v.argIndex + 1
+		}
+	}
+
+	return count
+}
+
+// Returns an error unless 'n' arguments, named 'name', are as many as the pattern's targets.
+func (p pattern) checkTargetCount(name string, n int) error {
+	switch count := p.targetCount(); {
+	case n == count:
+		return nil
+	case count == p.verbCount():
+		return fmt.Errorf("got %d '%s' for %d verbs; count must match", n, name, count)
+	default:
+		return fmt.Errorf("got %d '%s' for %d argument indexes; count must match", n, name, count)
+	}
+}
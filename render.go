@@ -0,0 +1,530 @@
+package unfmt
+
+import (
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Renders a value as text the verb would capture and assign back as an equal value.
+type renderFunc func(interface{}, verb) (string, error)
+
+var renderFuncs = map[string]renderFunc{
+	verbBool:     renderBool,
+	verbString:   renderString,
+	verbInt:      renderInt,
+	verbEpoch:    renderEpoch,
+	verbDuration: renderDuration,
+	verbIP:       renderStringer,
+	verbPrefix:   renderStringer,
+	verbAddrPort: renderStringer,
+	verbMAC:      renderStringer,
+	verbURL:      renderStringer,
+	verbUUID:     renderUUID,
+	verbVersion:  renderStringer,
+	verbQuantity: renderQuantity,
+	verbDecimal:  renderDecimal,
+	verbColor:    renderColor,
+	verbEnum:     renderEnum,
+	verbTime:     renderTime,
+}
+
+/*
+Renders 'values' by argument index into the format, in place of its verbs. A nil
+value, or nil pointer, is rendered as an empty capture for a verb with a default,
+or else as the first of any null tokens. Text shorter than its verb's max width
+is padded with spaces to that width, while text the verb wouldn't scan back in
+full, e.g. longer than its max width or containing a space it stops at, is an error.
+
+The result is scanned again to check that each verb captures the text rendered
+for it, as it may not where the texts of adjacent verbs run together, or where
+one contains the text around it in the format.
+*/
+func (p pattern) render(values []interface{}) (string, error) {
+	err := p.checkTargetCount("values", len(values))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	texts := make([]string, len(p.verbs))
+
+	index := 0
+	for i, v := range p.verbs {
+		b.WriteString(unescapeFormat(p.format[index:v.start]))
+
+		texts[i], err = p.renderVerb(values[v.argIndex], v)
+		if err != nil {
+			return "", fmt.Errorf("at index %d: %w", v.argIndex, err)
+		}
+
+		b.WriteString(texts[i])
+
+		// Pad after the text, since scanning stops at the space and skips the rest before the next verb.
+		if width, ok := v.maxWidth(); ok && len(texts[i]) < width {
+			b.WriteString(strings.Repeat(" ", width-len(texts[i])))
+		}
+
+		index = v.start + v.len()
+	}
+
+	b.WriteString(unescapeFormat(p.format[index:]))
+
+	str := b.String()
+
+	err = p.checkRescan(str, values, texts)
+	if err != nil {
+		return "", err
+	}
+
+	return str, nil
+}
+
+/*
+Scans 'str', as rendered from 'values', and returns an error unless each verb
+for a non-nil value captures the text rendered for it among 'texts'.
+*/
+func (p pattern) checkRescan(str string, values []interface{}, texts []string) error {
+	p.reset()
+
+	targets := p.rescanTargets(values)
+
+	err := p.capture(str, targets)
+	if err != nil {
+		return fmt.Errorf("rendered '%s' doesn't scan back: %w", str, err)
+	}
+
+	captures, err := p.assign(targets)
+	if err != nil {
+		return fmt.Errorf("rendered '%s' doesn't scan back: %w", str, err)
+	}
+
+	for i, c := range captures {
+		switch {
+		case isNil(values[c.verb.argIndex]):
+			continue
+		case c.null:
+			return fmt.Errorf("at index %d: '%s' rendered for verb '%s' scans back as a null token", c.verb.argIndex, texts[i], c.verb)
+		case c.text != texts[i]:
+			return fmt.Errorf("at index %d: rendered '%s' scans back '%s' for verb '%s' rather than '%s'", c.verb.argIndex, str, c.text, c.verb, texts[i])
+		}
+	}
+
+	return nil
+}
+
+/*
+Returns a new target to scan each of 'values' back into, of the value's own type,
+or for a nil or string value, the type its verb most naturally assigns to.
+*/
+func (p pattern) rescanTargets(values []interface{}) []interface{} {
+	targets := make([]interface{}, len(values))
+	for _, v := range p.verbs {
+		if targets[v.argIndex] != nil {
+			continue
+		}
+
+		rv := reflect.ValueOf(values[v.argIndex])
+		for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+
+		if !rv.IsValid() || rv.Kind() == reflect.Ptr || rv.Type() == reflect.TypeOf("") {
+			targets[v.argIndex] = p.newTarget(v)
+			continue
+		}
+
+		targets[v.argIndex] = reflect.New(rv.Type()).Interface()
+	}
+
+	return targets
+}
+
+// Reports whether 'value' is nil or a nil pointer, or a pointer to one.
+func isNil(value interface{}) bool {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	return !rv.IsValid() || rv.Kind() == reflect.Ptr
+}
+
+func (p pattern) renderVerb(value interface{}, v verb) (string, error) {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() || rv.Kind() == reflect.Ptr {
+		switch {
+		case v.hasDefault:
+			return "", nil
+		case len(p.nullTokens) > 0:
+			return p.nullTokens[0], nil
+		}
+
+		return "", fmt.Errorf("nil value for verb '%s' without a default or null tokens", v)
+	}
+
+	var text string
+	var err error
+
+	if v.repeat {
+		text, err = p.renderRepeated(rv.Interface(), v)
+		if err != nil {
+			return "", err
+		}
+	} else if str, ok := rv.Interface().(string); ok {
+		// A string is rendered as is, so must be text the verb scans in full.
+		n, err := p.assignTo(str, p.newTarget(v), v)
+		if err != nil {
+			return "", fmt.Errorf("'%s' can't be scanned by verb '%s': %w", str, v, err)
+		}
+
+		if n < len(str) {
+			return "", fmt.Errorf("only '%s' of '%s' would be scanned by verb '%s'", str[:n], str, v)
+		}
+
+		text = str
+	} else {
+		text, err = p.renderFuncs[v.value](rv.Interface(), v)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if v.stopAtSpaces() && strings.IndexFunc(text, unicode.IsSpace) >= 0 {
+		return "", fmt.Errorf("'%s' contains a space, at which verb '%s' would stop scanning", text, v)
+	}
+
+	if width, ok := v.maxWidth(); ok && len(text) > width {
+		return "", fmt.Errorf("'%s' exceeds max width %d of verb '%s'", text, width, v)
+	}
+
+	return text, nil
+}
+
+func renderBool(value interface{}, _ verb) (string, error) {
+	b, ok := value.(bool)
+	if !ok {
+		return "", fmt.Errorf("expected bool value, got %T", value)
+	}
+
+	return strconv.FormatBool(b), nil
+}
+
+// Returns a render func for bool values that renders the first of the given words for each.
+func newBoolWordsRenderFunc(boolWords []boolWord) renderFunc {
+	return func(value interface{}, _ verb) (string, error) {
+		b, ok := value.(bool)
+		if !ok {
+			return "", fmt.Errorf("expected bool value, got %T", value)
+		}
+
+		for _, bw := range boolWords {
+			if bw.value == b {
+				return bw.word, nil
+			}
+		}
+
+		return "", fmt.Errorf("no bool word given for %t", b)
+	}
+}
+
+func renderString(value interface{}, _ verb) (string, error) {
+	switch v := value.(type) {
+	case []byte:
+		return string(v), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+
+	return "", fmt.Errorf("expected string value, got %T", value)
+}
+
+func renderInt(value interface{}, _ verb) (string, error) {
+	if !isIntValue(value) {
+		return "", fmt.Errorf("expected integer value, got %T", value)
+	}
+
+	return fmt.Sprintf("%d", value), nil
+}
+
+func isIntValue(value interface{}) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	}
+
+	return false
+}
+
+// Renders a time as a Unix timestamp in the unit the verb's precision declares, with any remainder as a fraction.
+func renderEpoch(value interface{}, v verb) (string, error) {
+	t, ok := value.(time.Time)
+	if !ok {
+		return "", fmt.Errorf("expected time.Time value, got %T", value)
+	}
+
+	precision, _ := v.precision()
+	if precision > maxEpochPrecision {
+		return "", fmt.Errorf("precision %d exceeds max of %d for Unix timestamp", precision, maxEpochPrecision)
+	}
+
+	nanos := new(big.Int).Mul(big.NewInt(t.Unix()), big.NewInt(int64(pow10(maxEpochPrecision))))
+	nanos.Add(nanos, big.NewInt(int64(t.Nanosecond())))
+
+	units := new(big.Rat).SetFrac(nanos, big.NewInt(int64(pow10(maxEpochPrecision-precision))))
+
+	return trimFraction(units.FloatString(maxEpochPrecision - precision)), nil
+}
+
+func renderDuration(value interface{}, _ verb) (string, error) {
+	d, ok := value.(time.Duration)
+	if !ok {
+		return "", fmt.Errorf("expected time.Duration value, got %T", value)
+	}
+
+	return d.String(), nil
+}
+
+func renderStringer(value interface{}, _ verb) (string, error) {
+	switch v := value.(type) {
+	case net.IP:
+		return v.String(), nil
+	case net.IPNet:
+		return v.String(), nil
+	case url.URL:
+		return v.String(), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+
+	return "", fmt.Errorf("expected fmt.Stringer value, got %T", value)
+}
+
+func renderUUID(value interface{}, _ verb) (string, error) {
+	switch v := value.(type) {
+	case [16]byte:
+		digits := hex.EncodeToString(v[:])
+		return digits[:8] + "-" + digits[8:12] + "-" + digits[12:16] + "-" + digits[16:20] + "-" + digits[20:], nil
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		return string(text), err
+	}
+
+	return "", fmt.Errorf("expected [16]byte or encoding.TextMarshaler value, got %T", value)
+}
+
+func renderQuantity(value interface{}, _ verb) (string, error) {
+	switch v := value.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+
+	if !isIntValue(value) {
+		return "", fmt.Errorf("expected integer or float value, got %T", value)
+	}
+
+	return fmt.Sprintf("%d", value), nil
+}
+
+// Renders a decimal, from minor units for an integer value per the verb's precision.
+func renderDecimal(value interface{}, v verb) (string, error) {
+	precision, hasPrecision := v.precision()
+
+	var r *big.Rat
+	switch t := value.(type) {
+	case big.Rat:
+		r = &t
+	case encoding.TextMarshaler:
+		text, err := t.MarshalText()
+		return string(text), err
+	default:
+		if !isIntValue(value) {
+			return "", fmt.Errorf("expected integer, big.Rat or encoding.TextMarshaler value, got %T", value)
+		}
+
+		r, _ = new(big.Rat).SetString(fmt.Sprintf("%d", value))
+		r.Quo(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)))
+	}
+
+	if hasPrecision {
+		return r.FloatString(precision), nil
+	}
+
+	digits, ok := fractionDigits(r)
+	if !ok {
+		return "", fmt.Errorf("%s has no exact decimal representation", r)
+	}
+
+	return r.FloatString(digits), nil
+}
+
+// Returns how many fractional digits represent 'r' exactly in decimal, if any number does.
+func fractionDigits(r *big.Rat) (int, bool) {
+	denom := new(big.Int).Set(r.Denom())
+
+	var twos, fives int
+	for _, factor := range []struct {
+		n     int64
+		count *int
+	}{{2, &twos}, {5, &fives}} {
+		n, mod := big.NewInt(factor.n), new(big.Int)
+		for {
+			q, m := new(big.Int).QuoRem(denom, n, mod)
+			if m.Sign() != 0 {
+				break
+			}
+
+			denom = q
+			*factor.count++
+		}
+	}
+
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+
+	if twos > fives {
+		return twos, true
+	}
+
+	return fives, true
+}
+
+// Trims trailing zeros after a decimal point, and the point itself if nothing follows it.
+func trimFraction(str string) string {
+	if !strings.Contains(str, ".") {
+		return str
+	}
+
+	return strings.TrimSuffix(strings.TrimRight(str, "0"), ".")
+}
+
+func renderColor(value interface{}, _ verb) (string, error) {
+	c, ok := value.(color.Color)
+	if !ok {
+		return "", fmt.Errorf("expected color.Color value, got %T", value)
+	}
+
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if nrgba.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", nrgba.R, nrgba.G, nrgba.B), nil
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x%02x", nrgba.R, nrgba.G, nrgba.B, nrgba.A), nil
+}
+
+// Renders an enum value by its index among the verb's values, a string value being rendered as is.
+func renderEnum(value interface{}, v verb) (string, error) {
+	if !isIntValue(value) {
+		return "", fmt.Errorf("expected string or integer value, got %T", value)
+	}
+
+	index, err := strconv.Atoi(fmt.Sprintf("%d", value))
+	values := v.enumValues()
+	if err != nil || index < 0 || index >= len(values) {
+		return "", fmt.Errorf("enum index %d out of range for '%s'", value, v.options)
+	}
+
+	return values[index], nil
+}
+
+func renderTime(value interface{}, v verb) (string, error) {
+	t, ok := value.(time.Time)
+	if !ok {
+		return "", fmt.Errorf("expected time.Time value, got %T", value)
+	}
+
+	return t.Format(v.timeLayout()), nil
+}
+
+// Returns the value of the field of struct 'rv' at 'index', or nil if a nil embedded pointer is on the way.
+func readField(rv reflect.Value, index []int) interface{} {
+	v := rv
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v.Interface()
+}
+
+// Returns the values of the bound fields of struct 'rv', each nested one rendered per the format of its type.
+func structRenderValues(rv reflect.Value, fields []structField) ([]interface{}, error) {
+	values := make([]interface{}, len(fields))
+	for i, f := range fields {
+		values[i] = readField(rv, f.index)
+		if !f.nested || values[i] == nil || reflect.ValueOf(values[i]).Kind() == reflect.Ptr && reflect.ValueOf(values[i]).IsNil() {
+			continue
+		}
+
+		text, err := Marshal(values[i])
+		if err != nil {
+			return nil, fmt.Errorf("rendering field %s: %w", f.name, err)
+		}
+
+		values[i] = text
+	}
+
+	return values, nil
+}
+
+/*
+Marshal renders the struct 'v', or the struct it points to, per the format its type
+declares, as read by Unmarshal. A field whose type declares a format in turn is
+rendered per that format.
+*/
+func Marshal(v interface{}) (string, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return "", fmt.Errorf("%w: expected struct or struct pointer, got %T", ErrBadArg, v)
+	}
+
+	format, ok := formatOf(rv.Type())
+	if !ok {
+		return "", fmt.Errorf("%w: %s declares no format", ErrBadArg, rv.Type())
+	}
+
+	pattern, err := newPattern(format, options{})
+	if err != nil {
+		return "", fmt.Errorf("parsing format of %s: %w", rv.Type(), err)
+	}
+
+	fields, err := pattern.bindStruct(rv.Type())
+	if err != nil {
+		return "", err
+	}
+
+	values, err := structRenderValues(rv, fields)
+	if err != nil {
+		return "", err
+	}
+
+	return pattern.render(values)
+}
@@ -0,0 +1,228 @@
+package unfmt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+Splits the type of a long-form verb from any repetition following it, e.g. 'd'
+and ',*' for '%{d,*}', where the repetition is a separator and then a count.
+*/
+func splitRepeat(value string) (string, string) {
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if i < 0 {
+		return value, ""
+	}
+
+	return value[:i], value[i:]
+}
+
+/*
+Parses a verb's repetition, a separator followed by a count of '*' for any number
+of values, '+' for one or more, 'n' for exactly n or 'min..max', either of which
+may be omitted, e.g. ', 1..3' for one to three values separated by ', '.
+*/
+func (v *verb) parseRepetition(repetition string) error {
+	sep, count := repetition, ""
+	v.repeat, v.minCount, v.maxCount = true, 0, -1
+
+	switch {
+	case strings.HasSuffix(repetition, "*"):
+		sep = strings.TrimSuffix(repetition, "*")
+	case strings.HasSuffix(repetition, "+"):
+		sep, v.minCount = strings.TrimSuffix(repetition, "+"), 1
+	default:
+		i := len(repetition) - runSpan(reverse(repetition), digitRunes)
+		if j := strings.LastIndex(repetition[:i], ".."); j >= 0 && j+len("..") == i {
+			i = j - runSpan(reverse(repetition[:j]), digitRunes)
+		}
+
+		sep, count = repetition[:i], repetition[i:]
+		if count == "" {
+			return fmt.Errorf("has repetition '%s' without a count of '*', '+', 'n' or 'min..max'", repetition)
+		}
+
+		err := v.parseCount(count)
+		if err != nil {
+			return fmt.Errorf("has repetition '%s' with bad count: %w", repetition, err)
+		}
+	}
+
+	if sep == "" {
+		return fmt.Errorf("has repetition '%s' without a separator", repetition)
+	}
+
+	v.separator = sep
+
+	return nil
+}
+
+func (v *verb) parseCount(count string) error {
+	min, max := count, count
+	if i := strings.Index(count, ".."); i >= 0 {
+		min, max = count[:i], count[i+len(".."):]
+	}
+
+	var err error
+	if min != "" {
+		v.minCount, err = strconv.Atoi(min)
+		if err != nil {
+			return err
+		}
+	}
+
+	if max != "" {
+		v.maxCount, err = strconv.Atoi(max)
+		if err != nil {
+			return err
+		}
+
+		if v.maxCount < v.minCount {
+			return errors.New("min exceeds max")
+		}
+
+		if v.maxCount == 0 {
+			return errors.New("max of zero")
+		}
+	}
+
+	return nil
+}
+
+func reverse(str string) string {
+	b := []byte(str)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+
+	return string(b)
+}
+
+// Returns the verb each value of a repeated verb is assigned by, e.g. '%d' for '%{d,*}'.
+func (v verb) elem() verb {
+	v.repeat = false
+	v.hasDefault, v.defaultValue = false, ""
+
+	return v
+}
+
+/*
+Assigns the separated values at the start of 'str' to the slice 'target' points to,
+each as by the repeated verb's element verb, and checked against its constraints.
+Values are taken until the next separator or value is missing, and more than the
+verb's max count is an error. A nil target means the match is only being checked.
+*/
+func (p pattern) assignRepeated(str string, target interface{}, v verb) (int, error) {
+	elem := v.elem()
+
+	var slice reflect.Value
+	if target != nil {
+		ptr := reflect.ValueOf(target)
+		if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Slice {
+			return 0, fmt.Errorf("expected slice pointer as target, got %T", target)
+		}
+
+		slice = reflect.MakeSlice(ptr.Elem().Type(), 0, 0)
+	}
+
+	var n, count int
+	for i := 0; ; {
+		if count > 0 {
+			if !strings.HasPrefix(str[i:], v.separator) {
+				break
+			}
+
+			i += len(v.separator)
+		}
+
+		start := i + len(str[i:]) - len(strings.TrimLeftFunc(str[i:], unicode.IsSpace))
+
+		window := str[start:]
+		if end := strings.Index(window, v.separator); end >= 0 {
+			window = window[:end]
+		}
+		if end := strings.IndexFunc(window, unicode.IsSpace); end >= 0 && elem.stopAtSpaces() {
+			window = window[:end]
+		}
+
+		var elemTarget interface{}
+		if slice.IsValid() {
+			elemTarget = reflect.New(slice.Type().Elem()).Interface()
+		}
+
+		elemLen, err := p.assignTo(window, elemTarget, elem)
+		if err == nil && elemLen == 0 {
+			err = fmt.Errorf("expected value at start of '%s'", window)
+		}
+		if err == nil {
+			err = elem.checkConstraints(window[:elemLen])
+		}
+
+		if err != nil {
+			if count < v.minCount {
+				return 0, fmt.Errorf("value %d: %w", count+1, err)
+			}
+
+			break
+		}
+
+		if count == v.maxCount {
+			return 0, fmt.Errorf("found more than %d values in '%s'", v.maxCount, str)
+		}
+
+		if slice.IsValid() {
+			slice = reflect.Append(slice, reflect.ValueOf(elemTarget).Elem())
+		}
+
+		i = start + elemLen
+		n = i
+		count++
+	}
+
+	if count < v.minCount {
+		return 0, fmt.Errorf("found %d values, fewer than %d, in '%s'", count, v.minCount, str)
+	}
+
+	if slice.IsValid() {
+		reflect.ValueOf(target).Elem().Set(slice)
+	}
+
+	return n, nil
+}
+
+// Renders the elements of the slice 'value' per a repeated verb, joined by its separator.
+func (p pattern) renderRepeated(value interface{}, v verb) (string, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return "", fmt.Errorf("expected slice value, got %T", value)
+	}
+
+	if rv.Len() < v.minCount || v.maxCount >= 0 && rv.Len() > v.maxCount {
+		return "", fmt.Errorf("got %d values for verb '%s'", rv.Len(), v)
+	}
+
+	elem := v.elem()
+
+	texts := make([]string, rv.Len())
+	for i := range texts {
+		text, err := p.renderVerb(rv.Index(i).Interface(), elem)
+		if err != nil {
+			return "", fmt.Errorf("value %d: %w", i+1, err)
+		}
+
+		if strings.Contains(text, v.separator) {
+			return "", fmt.Errorf("value %d: '%s' contains separator '%s' of verb '%s'", i+1, text, v.separator, v)
+		}
+
+		texts[i] = text
+	}
+
+	return strings.Join(texts, v.separator), nil
+}
@@ -6,9 +6,23 @@ import (
 )
 
 const (
-	verbBool   rune = 't'
-	verbInt    rune = 'd'
-	verbString rune = 's'
+	verbBool     string = "t"
+	verbInt      string = "d"
+	verbString   string = "s"
+	verbEpoch    string = "T"
+	verbDuration string = "D"
+	verbIP       string = "I"
+	verbPrefix   string = "P"
+	verbAddrPort string = "A"
+	verbMAC      string = "M"
+	verbURL      string = "L"
+	verbUUID     string = "u"
+	verbVersion  string = "V"
+	verbQuantity string = "H"
+	verbDecimal  string = "m"
+	verbColor    string = "C"
+	verbEnum     string = "enum"
+	verbTime     string = "time"
 	// TODO: Add missing verbs.
 )
 
@@ -34,11 +48,16 @@ type Scanner struct {
 	p *pattern
 }
 
-// NewScanner initializes a Scanner from a format string.
-func NewScanner(format string) (Scanner, error) {
+// NewScanner initializes a Scanner from a format string, configured by any 'opts'.
+func NewScanner(format string, opts ...Option) (Scanner, error) {
 	var s Scanner
 
-	p, err := newPattern(format)
+	o, err := newOptions(opts)
+	if err != nil {
+		return s, fmt.Errorf("initializing new scanner from 'opts': %w", err)
+	}
+
+	p, err := newPattern(format, o)
 	if err != nil {
 		return s, fmt.Errorf("initializing new scanner from 'format': %w", err)
 	}
@@ -54,18 +73,19 @@ func (s Scanner) ScanString(str string, targetPtrs ...interface{}) error {
 		return fmt.Errorf("%w: 'str' must not be empty", ErrBadArg)
 	}
 
-	if len(targetPtrs) != s.p.verbCount() {
-		return fmt.Errorf("got %d 'targetPtrs' for %d verbs; count must match", len(targetPtrs), s.p.verbCount())
+	err := s.p.checkTargetCount("targetPtrs", len(targetPtrs))
+	if err != nil {
+		return err
 	}
 
 	s.p.reset()
 
-	err := s.p.capture(str)
+	err = s.p.capture(str, targetPtrs)
 	if err != nil {
 		return fmt.Errorf("capturing from 'str': %w", err)
 	}
 
-	err = s.p.assign(targetPtrs)
+	_, err = s.p.assign(targetPtrs)
 	if err != nil {
 		return fmt.Errorf("assigning values to 'targetPtrs': %w", err)
 	}
@@ -89,24 +109,202 @@ func ScanString(str, format string, targetPtrs ...interface{}) error {
 		return fmt.Errorf("%w: one or more 'targetPtrs' required", ErrBadArg)
 	}
 
-	pattern, err := newPattern(format)
+	pattern, err := newPattern(format, options{})
 	if err != nil {
 		return fmt.Errorf("parsing 'format': %w", err)
 	}
 
-	if len(targetPtrs) != pattern.verbCount() {
-		return fmt.Errorf("got %d 'targetPtrs' for %d verbs; count must match", len(targetPtrs), pattern.verbCount())
+	err = pattern.checkTargetCount("targetPtrs", len(targetPtrs))
+	if err != nil {
+		return err
 	}
 
-	err = pattern.capture(str)
+	err = pattern.capture(str, targetPtrs)
 	if err != nil {
 		return fmt.Errorf("capturing from 'str': %w", err)
 	}
 
-	err = pattern.assign(targetPtrs)
+	_, err = pattern.assign(targetPtrs)
 	if err != nil {
 		return fmt.Errorf("assigning values to 'targetPtrs': %w", err)
 	}
 
 	return nil
 }
+
+/*
+ScanMap captures values from 'str' according to the Scanner's state and returns
+them by the names of their verbs, e.g. 'user' for '%{user:s}'. Each value has the
+type its verb most naturally assigns to: int64 for '%d', bool for '%t', string
+for '%s', time.Time for '%T' and so on. A value captured as a null token is nil.
+
+Every verb must be named, or share its argument index with a verb that is.
+*/
+func (s Scanner) ScanMap(str string) (map[string]interface{}, error) {
+	if str == "" {
+		return nil, fmt.Errorf("%w: 'str' must not be empty", ErrBadArg)
+	}
+
+	names, err := s.p.targetNames()
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := s.p.newTargets(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.p.reset()
+
+	err = s.p.capture(str, targets)
+	if err != nil {
+		return nil, fmt.Errorf("capturing from 'str': %w", err)
+	}
+
+	captures, err := s.p.assign(targets)
+	if err != nil {
+		return nil, fmt.Errorf("assigning values: %w", err)
+	}
+
+	values := make(map[string]interface{}, len(names))
+	for i, value := range targetValues(targets, captures) {
+		values[names[i]] = value
+	}
+
+	return values, nil
+}
+
+// ScanMap captures values from 'str' according to 'format' and returns them by name, as does Scanner.ScanMap.
+func ScanMap(str, format string) (map[string]interface{}, error) {
+	if format == "" {
+		return nil, fmt.Errorf("%w: 'format' must not be empty", ErrBadArg)
+	}
+
+	pattern, err := newPattern(format, options{})
+	if err != nil {
+		return nil, fmt.Errorf("parsing 'format': %w", err)
+	}
+
+	return Scanner{p: &pattern}.ScanMap(str)
+}
+
+/*
+ScanValues captures values from 'str' according to the Scanner's state and
+returns them in the order ScanString would assign them to 'targetPtrs', each
+of the type its verb most naturally assigns to, as for ScanMap, except that
+'%d' values are ints. A value captured as a null token is nil.
+*/
+func (s Scanner) ScanValues(str string) ([]interface{}, error) {
+	if str == "" {
+		return nil, fmt.Errorf("%w: 'str' must not be empty", ErrBadArg)
+	}
+
+	targets, err := s.p.newTargets(valueTargetFuncs)
+	if err != nil {
+		return nil, err
+	}
+
+	s.p.reset()
+
+	err = s.p.capture(str, targets)
+	if err != nil {
+		return nil, fmt.Errorf("capturing from 'str': %w", err)
+	}
+
+	captures, err := s.p.assign(targets)
+	if err != nil {
+		return nil, fmt.Errorf("assigning values: %w", err)
+	}
+
+	return targetValues(targets, captures), nil
+}
+
+// ScanValues captures values from 'str' according to 'format' and returns them in order, as does Scanner.ScanValues.
+func ScanValues(str, format string) ([]interface{}, error) {
+	if format == "" {
+		return nil, fmt.Errorf("%w: 'format' must not be empty", ErrBadArg)
+	}
+
+	pattern, err := newPattern(format, options{})
+	if err != nil {
+		return nil, fmt.Errorf("parsing 'format': %w", err)
+	}
+
+	return Scanner{p: &pattern}.ScanValues(str)
+}
+
+/*
+ScanStruct captures values from 'str' according to the Scanner's state and assigns
+them to the fields of the struct 'v' points to by the names of their verbs, e.g. a
+field tagged 'unfmt:"user"' or else named User for '%{user:s}'. Fields of embedded
+structs are promoted as in encoding/json, and nil pointers on the way to a field,
+or in its type, are allocated as needed. A field whose type declares its own format,
+per Unmarshal, is scanned from the text captured for it in turn.
+
+If the Scanner was initialized WithStruct, 'v' must point to that type.
+*/
+func (s Scanner) ScanStruct(str string, v interface{}) error {
+	if str == "" {
+		return fmt.Errorf("%w: 'str' must not be empty", ErrBadArg)
+	}
+
+	rv, err := structValue(v)
+	if err != nil {
+		return err
+	}
+
+	fields := s.p.structFields
+	if s.p.structType == nil {
+		fields, err = s.p.bindStruct(rv.Type())
+		if err != nil {
+			return err
+		}
+	} else if rv.Type() != s.p.structType {
+		return fmt.Errorf("%w: expected %s pointer as 'v' per WithStruct, got %T", ErrBadArg, s.p.structType, v)
+	}
+
+	s.p.reset()
+
+	err = s.p.capture(str, newFieldTargets(fields))
+	if err != nil {
+		return fmt.Errorf("capturing from 'str': %w", err)
+	}
+
+	targets := fieldTargets(rv, fields)
+
+	captures, err := s.p.assign(targets)
+	if err != nil {
+		return fmt.Errorf("assigning values to 'v': %w", err)
+	}
+
+	return unmarshalNested(str, rv, fields, targetValues(targets, captures))
+}
+
+// ScanStruct captures values from 'str' according to 'format' and assigns them to the fields of 'v', as does Scanner.ScanStruct.
+func ScanStruct(str, format string, v interface{}) error {
+	if format == "" {
+		return fmt.Errorf("%w: 'format' must not be empty", ErrBadArg)
+	}
+
+	pattern, err := newPattern(format, options{})
+	if err != nil {
+		return fmt.Errorf("parsing 'format': %w", err)
+	}
+
+	return Scanner{p: &pattern}.ScanStruct(str, v)
+}
+
+/*
+Render prints 'values', or the values they point to, in place of the verbs in the
+Scanner's format, so that scanning the result assigns equal values. Values are
+taken in the order ScanString assigns to 'targetPtrs' and rendered per their verbs,
+e.g. a time.Time per the layout of '%{time:2006-01-02}'. A nil value is rendered as
+an empty capture for a verb with a default, or else as the first null token. The
+result is scanned again before it's returned, and a value whose text its verb doesn't
+capture back, e.g. a string the verb doesn't accept or text running into the next
+verb's, is an error.
+*/
+func (s Scanner) Render(values ...interface{}) (string, error) {
+	return s.p.render(values)
+}
@@ -1,9 +1,19 @@
 package unfmt
 
 import (
+	"errors"
 	"fmt"
+	"image/color"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
+	"unicode"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -13,8 +23,41 @@ var (
 	stringVal1, stringVal2, stringVal3 string
 	intVal1, intVal2, intVal3          int
 	int64Val1, int64Val2, int64Val3    int64
+	timeVal1, timeVal2, timeVal3       time.Time
+	durVal1, durVal2, durVal3          time.Duration
+	addrVal1, addrVal2                 netip.Addr
+	prefixVal1                         netip.Prefix
+	addrPortVal1, addrPortVal2         netip.AddrPort
+	ipVal1                             net.IP
+	macVal1                            net.HardwareAddr
+	urlVal1                            url.URL
+	uuidVal1                           [16]byte
+	textUUIDVal1                       textUUID
+	versionVal1, versionVal2           Version
+	uint8Val1                          uint8
+	uint64Val1                         uint64
+	float64Val1                        float64
+	ratVal1                            big.Rat
+	nrgbaVal1, nrgbaVal2               color.NRGBA
+	rgbaVal1                           color.RGBA
+	intsVal1                           []int
+	stringsVal1                        []string
 )
 
+// Stands in for a third-party UUID type.
+type textUUID struct {
+	text string
+}
+
+func (u *textUUID) UnmarshalText(text []byte) error {
+	u.text = string(text)
+	return nil
+}
+
+func (u textUUID) MarshalText() ([]byte, error) {
+	return []byte(u.text), nil
+}
+
 func TestScanString(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -101,6 +144,23 @@ func TestScanString(t *testing.T) {
 				assert.Equal(t, 30000, intVal3)
 			},
 		},
+		{
+			name:   "handles digits after '.' as max width for verbs without precision",
+			format: "%.3s%s %.2d%d",
+			str:    "abcdef 12345",
+			targetPtrs: []interface{}{
+				&stringVal1,
+				&stringVal2,
+				&intVal1,
+				&intVal2,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "abc", stringVal1)
+				assert.Equal(t, "def", stringVal2)
+				assert.Equal(t, 12, intVal1)
+				assert.Equal(t, 345, intVal2)
+			},
+		},
 		{
 			name:   "takes less than max width if whitespace encountered",
 			format: "%3d%4d%d",
@@ -215,6 +275,416 @@ func TestScanString(t *testing.T) {
 				assert.Equal(t, 4, intVal3)
 			},
 		},
+		{
+			name:   "handles Unix timestamps",
+			format: "start=%T end=%.3T at %.9T",
+			str:    "start=1697040000 end=1697040000123 at 1697040000000000042",
+			targetPtrs: []interface{}{
+				&timeVal1,
+				&timeVal2,
+				&timeVal3,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, time.Unix(1697040000, 0).UTC(), timeVal1)
+				assert.Equal(t, time.Unix(1697040000, 123e6).UTC(), timeVal2)
+				assert.Equal(t, time.Unix(1697040000, 42).UTC(), timeVal3)
+			},
+		},
+		{
+			name:   "handles fractional Unix timestamps",
+			format: "%T|%.3T|%T",
+			str:    "1697040000.123|1697040000123.5|-1.5",
+			targetPtrs: []interface{}{
+				&timeVal1,
+				&timeVal2,
+				&timeVal3,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, time.Unix(1697040000, 123e6).UTC(), timeVal1)
+				assert.Equal(t, time.Unix(1697040000, 123500000).UTC(), timeVal2)
+				assert.Equal(t, time.Unix(-2, 5e8).UTC(), timeVal3)
+			},
+		},
+		{
+			name:   "handles Unix timestamps adjacent to other verbs",
+			format: "%.6T%s",
+			str:    "1697040000123456.7us",
+			targetPtrs: []interface{}{
+				&timeVal1,
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, time.Unix(1697040000, 123456700).UTC(), timeVal1)
+				assert.Equal(t, "us", stringVal1)
+			},
+		},
+		{
+			name:   "handles Go durations",
+			format: "took %D, then %D, then %D",
+			str:    "took 1h2m3.5s, then 250ms, then -1.5µs",
+			targetPtrs: []interface{}{
+				&durVal1,
+				&durVal2,
+				&durVal3,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, time.Hour+2*time.Minute+3500*time.Millisecond, durVal1)
+				assert.Equal(t, 250*time.Millisecond, durVal2)
+				assert.Equal(t, -1500*time.Nanosecond, durVal3)
+			},
+		},
+		{
+			name:   "handles ISO-8601 durations",
+			format: "%D/%D/%D",
+			str:    "PT1H30M/P1DT12H/-PT0,5S",
+			targetPtrs: []interface{}{
+				&durVal1,
+				&durVal2,
+				&durVal3,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, 90*time.Minute, durVal1)
+				assert.Equal(t, 36*time.Hour, durVal2)
+				assert.Equal(t, -500*time.Millisecond, durVal3)
+			},
+		},
+		{
+			name:   "handles durations adjacent to other verbs",
+			format: "%D%s|%D%d",
+			str:    "5minutes|0 10",
+			targetPtrs: []interface{}{
+				&durVal1,
+				&stringVal1,
+				&durVal2,
+				&intVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, 5*time.Minute, durVal1)
+				assert.Equal(t, "inutes", stringVal1)
+				assert.Equal(t, time.Duration(0), durVal2)
+				assert.Equal(t, 10, intVal1)
+			},
+		},
+		{
+			name:   "handles IP addresses",
+			format: "from %I:%d to %I via %I",
+			str:    "from 10.0.0.1:443 to fe80::1%eth0 via 2001:db8::ff",
+			targetPtrs: []interface{}{
+				&addrVal1,
+				&intVal1,
+				&addrVal2,
+				&ipVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, netip.MustParseAddr("10.0.0.1"), addrVal1)
+				assert.Equal(t, 443, intVal1)
+				assert.Equal(t, netip.MustParseAddr("fe80::1%eth0"), addrVal2)
+				assert.True(t, net.ParseIP("2001:db8::ff").Equal(ipVal1))
+			},
+		},
+		{
+			name:   "handles CIDR prefixes and hardware addresses",
+			format: "route %P%s dev %M%s",
+			str:    "route 2001:db8::/32,static dev aa:bb:cc:dd:ee:ff;up",
+			targetPtrs: []interface{}{
+				&prefixVal1,
+				&stringVal1,
+				&macVal1,
+				&stringVal2,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, netip.MustParsePrefix("2001:db8::/32"), prefixVal1)
+				assert.Equal(t, ",static", stringVal1)
+				assert.Equal(t, net.HardwareAddr{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}, macVal1)
+				assert.Equal(t, ";up", stringVal2)
+			},
+		},
+		{
+			name:   "handles IP address and port pairs",
+			format: "%A->%A%s",
+			str:    "10.0.0.1:52114->[2001:db8::1]:443/tcp",
+			targetPtrs: []interface{}{
+				&addrPortVal1,
+				&addrPortVal2,
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, netip.MustParseAddrPort("10.0.0.1:52114"), addrPortVal1)
+				assert.Equal(t, netip.MustParseAddrPort("[2001:db8::1]:443"), addrPortVal2)
+				assert.Equal(t, "/tcp", stringVal1)
+			},
+		},
+		{
+			name:   "handles URLs",
+			format: `"GET %L HTTP/1.1" referer=%L"`,
+			str:    `"GET /search?q=50%25+off&page=2 HTTP/1.1" referer=https://example.com/a?b=c"`,
+			targetPtrs: []interface{}{
+				&urlVal1,
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "/search", urlVal1.Path)
+				assert.Equal(t, "50% off", urlVal1.Query().Get("q"))
+				assert.Equal(t, "2", urlVal1.Query().Get("page"))
+				assert.Equal(t, "https://example.com/a?b=c", stringVal1)
+			},
+		},
+		{
+			name:   "handles UUIDs",
+			format: "trace=%u span=%u%d parent=%u",
+			str:    "trace=F81D4FAE-7DEC-11D0-A765-00A0C91E6BF6 span=f81d4fae7dec11d0a76500a0c91e6bf642 parent={f81d4fae-7dec-11d0-a765-00a0c91e6bf6}",
+			targetPtrs: []interface{}{
+				&uuidVal1,
+				&stringVal1,
+				&intVal1,
+				&textUUIDVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, [16]byte{0xf8, 0x1d, 0x4f, 0xae, 0x7d, 0xec, 0x11, 0xd0, 0xa7, 0x65, 0x00, 0xa0, 0xc9, 0x1e, 0x6b, 0xf6}, uuidVal1)
+				assert.Equal(t, "f81d4fae7dec11d0a76500a0c91e6bf6", stringVal1)
+				assert.Equal(t, 42, intVal1)
+				assert.Equal(t, "{f81d4fae-7dec-11d0-a765-00a0c91e6bf6}", textUUIDVal1.text)
+			},
+		},
+		{
+			name:   "handles semantic versions",
+			format: "deployed %V over %V (%V)",
+			str:    "deployed v1.24.3-rc.1+build.5 over 1.24.2 (v2.0.0-alpha-1.)",
+			targetPtrs: []interface{}{
+				&versionVal1,
+				&versionVal2,
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, Version{Major: 1, Minor: 24, Patch: 3, Prerelease: []string{"rc", "1"}, Build: []string{"build", "5"}}, versionVal1)
+				assert.Equal(t, Version{Major: 1, Minor: 24, Patch: 2}, versionVal2)
+				assert.Equal(t, "v2.0.0-alpha-1", stringVal1)
+			},
+		},
+		{
+			name:   "handles quantities with unit prefixes",
+			format: "disk=%H mem=%#H rate=%H/s cpu=%H",
+			str:    "disk=1.5GiB mem=512K rate=3.2M/s cpu=250",
+			targetPtrs: []interface{}{
+				&int64Val1,
+				&int64Val2,
+				&float64Val1,
+				&intVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, int64(1610612736), int64Val1)
+				assert.Equal(t, int64(524288), int64Val2)
+				assert.Equal(t, 3.2e6, float64Val1)
+				assert.Equal(t, 250, intVal1)
+			},
+		},
+		{
+			name:   "handles quantities adjacent to other verbs",
+			format: "%H%s",
+			str:    "10kBps",
+			targetPtrs: []interface{}{
+				&intVal1,
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, 10000, intVal1)
+				assert.Equal(t, "ps", stringVal1)
+			},
+		},
+		{
+			name:   "handles exact decimals",
+			format: "USD %.2m, EUR %.2m, rate %m",
+			str:    "USD 1234.56, EUR -7.5, rate 0.000123456789012345678901",
+			targetPtrs: []interface{}{
+				&int64Val1,
+				&int64Val2,
+				&ratVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, int64(123456), int64Val1)
+				assert.Equal(t, int64(-750), int64Val2)
+
+				expected, _ := new(big.Rat).SetString("0.000123456789012345678901")
+				assert.Equal(t, 0, expected.Cmp(&ratVal1))
+			},
+		},
+		{
+			name:   "handles colors",
+			format: "fg: %C; bg: %C; border: %C;",
+			str:    "fg: #fa0; bg: rgb(10, 20,30 ); border: #ff000080;",
+			targetPtrs: []interface{}{
+				&nrgbaVal1,
+				&nrgbaVal2,
+				&rgbaVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, color.NRGBA{R: 0xff, G: 0xaa, B: 0x00, A: 0xff}, nrgbaVal1)
+				assert.Equal(t, color.NRGBA{R: 10, G: 20, B: 30, A: 0xff}, nrgbaVal2)
+				assert.Equal(t, color.RGBA{R: 0x80, G: 0x00, B: 0x00, A: 0x80}, rgbaVal1)
+			},
+		},
+		{
+			name:   "handles colors adjacent to other verbs",
+			format: "%C%s",
+			str:    "rgb(0, 128, 255) is azure",
+			targetPtrs: []interface{}{
+				&nrgbaVal1,
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, color.NRGBA{R: 0, G: 128, B: 255, A: 0xff}, nrgbaVal1)
+				assert.Equal(t, "is", stringVal1)
+			},
+		},
+		{
+			name:   "handles enums",
+			format: "[%{enum:DEBUG|INFO|WARN|WARNING|ERROR}] %{enum:GET|POST|PUT}%s",
+			str:    "[WARNING] POST/users",
+			targetPtrs: []interface{}{
+				&stringVal1,
+				&intVal1,
+				&stringVal2,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "WARNING", stringVal1)
+				assert.Equal(t, 1, intVal1)
+				assert.Equal(t, "/users", stringVal2)
+			},
+		},
+		{
+			name:   "handles enum values with spaces",
+			format: "status: %{enum:OK|NOT FOUND}, %d",
+			str:    "status: NOT FOUND, 404",
+			targetPtrs: []interface{}{
+				&stringVal1,
+				&intVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "NOT FOUND", stringVal1)
+				assert.Equal(t, 404, intVal1)
+			},
+		},
+		{
+			name:   "handles enum values that are verb types",
+			format: "%{enum:s|m|l} %{enum:t|f} %{enum:time|date} %{enum:d}",
+			str:    "m f date d",
+			targetPtrs: []interface{}{
+				&intVal1,
+				&intVal2,
+				&intVal3,
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, 1, intVal1)
+				assert.Equal(t, 1, intVal2)
+				assert.Equal(t, 1, intVal3)
+				assert.Equal(t, "d", stringVal1)
+			},
+		},
+		{
+			name:   "handles named enum values beginning with verb types",
+			format: "%{size:enum:s-1|s-2}",
+			str:    "s-2",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "s-2", stringVal1)
+			},
+		},
+		{
+			name:   "handles unnamed enum values beginning with verb types",
+			format: "%{enum:s-1|s-2}",
+			str:    "s-1",
+			targetPtrs: []interface{}{
+				&intVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, 0, intVal1)
+			},
+		},
+		{
+			name:   "handles range and length constraints",
+			format: "user %{s:len=3..16} on port %{d:1..65535}",
+			str:    "user lola on port 8080",
+			targetPtrs: []interface{}{
+				&stringVal1,
+				&intVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "lola", stringVal1)
+				assert.Equal(t, 8080, intVal1)
+			},
+		},
+		{
+			name:   "rejects candidates violating constraints",
+			format: "%{s:len=1..3}-%s",
+			str:    "ab-cd-ef",
+			targetPtrs: []interface{}{
+				&stringVal1,
+				&stringVal2,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "ab", stringVal1)
+				assert.Equal(t, "cd-ef", stringVal2)
+			},
+		},
+		{
+			name:   "rejects candidates with invalid values",
+			format: "%I:%d",
+			str:    "fe80::1:22",
+			targetPtrs: []interface{}{
+				&addrVal1,
+				&intVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, netip.MustParseAddr("fe80::1"), addrVal1)
+				assert.Equal(t, 22, intVal1)
+			},
+		},
+		{
+			name:   "checks candidates against the types of the targets",
+			format: "%s,%d",
+			str:    "a,b,18446744073709551615",
+			targetPtrs: []interface{}{
+				&stringVal1,
+				&uint64Val1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "a,b", stringVal1)
+				assert.Equal(t, uint64(18446744073709551615), uint64Val1)
+			},
+		},
+		{
+			name:   "handles defaults for empty captures",
+			format: "%{s=none},%{d=0},%{d=-1},%{t=true}",
+			str:    ",,  42,",
+			targetPtrs: []interface{}{
+				&stringVal1,
+				&intVal1,
+				&intVal2,
+				&boolVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "none", stringVal1)
+				assert.Equal(t, 0, intVal1)
+				assert.Equal(t, 42, intVal2)
+				assert.Equal(t, true, boolVal1)
+			},
+		},
+		{
+			name:   "handles defaults for adjacent verbs",
+			format: "%5s%{d=7}",
+			str:    "  abc   ",
+			targetPtrs: []interface{}{
+				&stringVal1,
+				&intVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "abc", stringVal1)
+				assert.Equal(t, 7, intVal1)
+			},
+		},
 		{
 			name:   "returns error for unsupported verb",
 			format: "%s was a very good %z",
@@ -336,141 +806,1390 @@ func TestScanString(t *testing.T) {
 			shouldError:   true,
 			expectedError: `assigning values to 'targetPtrs': at index 1: error converting '' to integer: strconv.ParseInt: parsing "": invalid syntax`,
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			// _, err := fmt.Sscanf(tc.str, tc.format, tc.targetPtrs...)
-			err := ScanString(tc.str, tc.format, tc.targetPtrs...)
-			if tc.shouldError {
-				assert.Error(t, err)
-				assert.EqualError(t, err, tc.expectedError)
-				return
-			}
-
-			assert.NoError(t, err)
-			tc.assertResult(t)
-		})
-	}
-}
-
-const story = `Once upon a time, there was a cat named Lola. 
-She liked to curl up in our yard. 
-Her favorite color is yellow and her favorite number is 3, but that's silly, because she's a cat.`
-
-func BenchmarkScanString(b *testing.B) {
-	var number string
-	var three int
+		{
+			name:   "returns error for wrong Unix timestamp target type",
+			format: "at %T",
+			str:    "at 1697040000",
+			targetPtrs: []interface{}{
+				&int64Val1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: expected time.Time pointer as target, got *int64",
+		},
+		{
+			name:   "returns error for ISO-8601 duration with months",
+			format: "every %D",
+			str:    "every P1M",
+			targetPtrs: []interface{}{
+				&durVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: error converting 'P1M' to duration: designator 'M' has no fixed duration",
+		},
+		{
+			name:   "returns error for invalid IP address",
+			format: "client %I",
+			str:    "client 300.1.1.1",
+			targetPtrs: []interface{}{
+				&addrVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: expected leading IP address, got '300.1.1.1'",
+		},
+		{
+			name:   "returns error for URL with invalid percent-encoding",
+			format: "url=%L",
+			str:    "url=%zz",
+			targetPtrs: []interface{}{
+				&urlVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: expected leading URL, got '%zz'",
+		},
+		{
+			name:   "returns error for malformed UUID",
+			format: "id=%u",
+			str:    "id=f81d4fae-7dec-11d0-a765-00a0c91e6bf",
+			targetPtrs: []interface{}{
+				&uuidVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: expected leading UUID, got 'f81d4fae-7dec-11d0-a765-00a0c91e6bf'",
+		},
+		{
+			name:   "returns error for fractional quantity with integer target",
+			format: "size: %H",
+			str:    "size: 1.0001K",
+			targetPtrs: []interface{}{
+				&intVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: error converting '1.0001K' to quantity: 1000.100 is not a whole number",
+		},
+		{
+			name:   "returns error for quantity overflowing target",
+			format: "size: %H",
+			str:    "size: 1Ki",
+			targetPtrs: []interface{}{
+				&uint8Val1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: error converting '1Ki' to quantity: value out of range",
+		},
+		{
+			name:   "returns error for decimal exceeding precision",
+			format: "USD %.2m",
+			str:    "USD 1234.567",
+			targetPtrs: []interface{}{
+				&int64Val1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: '1234.567' has 3 fractional digits, more than precision of 2",
+		},
+		{
+			name:   "returns error for decimal with float target",
+			format: "USD %.2m",
+			str:    "USD 1234.56",
+			targetPtrs: []interface{}{
+				&float64Val1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: expected integer, big.Rat, encoding.TextUnmarshaler or string pointer as target, got *float64",
+		},
+		{
+			name:   "returns error for invalid semantic version with string target",
+			format: "version %V",
+			str:    "version v1.0.0-01",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: error converting 'v1.0.0-01' to semantic version: numeric prerelease identifier '01' has leading zero",
+		},
+		{
+			name:   "returns error for out of range rgb() component",
+			format: "color %C",
+			str:    "color rgb(256, 0, 0)",
+			targetPtrs: []interface{}{
+				&nrgbaVal1,
+			},
+			shouldError:   true,
+			expectedError: `assigning values to 'targetPtrs': at index 0: error converting rgb() component in 'rgb(256, 0, 0)': strconv.ParseUint: parsing "256": value out of range`,
+		},
+		{
+			name:   "returns error for value not in enum",
+			format: "level=%{enum:DEBUG|INFO}",
+			str:    "level=TRACE",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: expected leading one of 'DEBUG|INFO', got 'TRACE'",
+		},
+		{
+			name:   "returns error for enum without values",
+			format: "level=%{enum}",
+			str:    "level=TRACE",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 9: verb '%%{enum}' requires one or more '|'-separated values", ErrBadArg),
+		},
+		{
+			name:   "returns error for unclosed long form verb",
+			format: "level=%{enum:DEBUG|INFO",
+			str:    "level=INFO",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 7: missing '}' to close verb '%%{enum:DEBUG|INFO'", ErrBadArg),
+		},
+		{
+			name:   "returns error for capture violating range constraint",
+			format: "port %{d:1..65535}",
+			str:    "port 70000",
+			targetPtrs: []interface{}{
+				&intVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: '70000' captured for verb '%{d:1..65535}' violates constraint '1..65535'",
+		},
+		{
+			name:   "returns error for range constraint on non-numeric verb",
+			format: "user %{s:1..10}",
+			str:    "user lola",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 10: verb '%%{s:1..10}' supports no value range constraint such as '1..10'", ErrBadArg),
+		},
+		{
+			name:   "returns error for malformed constraint",
+			format: "port %{d:10..1}",
+			str:    "port 5",
+			targetPtrs: []interface{}{
+				&intVal1,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 10: verb '%%{d:10..1}' has bad constraint '10..1': min exceeds max", ErrBadArg),
+		},
+		{
+			name:   "returns error for invalid default",
+			format: "%{d=zero}",
+			str:    "1",
+			targetPtrs: []interface{}{
+				&intVal1,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 5: bad default 'zero' for verb '%%{d=zero}': expected one or more leading numeric characters, got 'zero'", ErrBadArg),
+		},
+		{
+			name:   "handles named long form verbs",
+			format: "%{user:s} logged in on port %{port:d:1..65535}",
+			str:    "lola logged in on port 8080",
+			targetPtrs: []interface{}{
+				&stringVal1,
+				&intVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "lola", stringVal1)
+				assert.Equal(t, 8080, intVal1)
+			},
+		},
+		{
+			name:   "handles time with layout",
+			format: "date=%{ts:time:2006-01-02} ok",
+			str:    "date=2023-10-11 ok",
+			targetPtrs: []interface{}{
+				&timeVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, time.Date(2023, 10, 11, 0, 0, 0, 0, time.UTC), timeVal1)
+			},
+		},
+		{
+			name:   "handles time with layout containing colons and spaces",
+			format: "at %{time:2006-01-02 15:04:05}.",
+			str:    "at 2023-10-11 13:45:00.",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "2023-10-11 13:45:00", stringVal1)
+			},
+		},
+		{
+			name:   "handles time in RFC 3339 by default",
+			format: "%{time}|%s",
+			str:    "2023-10-11T13:45:00Z|done",
+			targetPtrs: []interface{}{
+				&timeVal1,
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, time.Date(2023, 10, 11, 13, 45, 0, 0, time.UTC), timeVal1)
+				assert.Equal(t, "done", stringVal1)
+			},
+		},
+		{
+			name:   "handles escapes in long form verbs",
+			format: `%{sep:enum:a\|b|a\}|\:}!`,
+			str:    "a}!",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "a}", stringVal1)
+			},
+		},
+		{
+			name:   "handles literal percent signs in and around long form verbs",
+			format: "%{d}%% of %{enum:50%|100%}",
+			str:    "30% of 100%",
+			targetPtrs: []interface{}{
+				&intVal1,
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, 30, intVal1)
+				assert.Equal(t, "100%", stringVal1)
+			},
+		},
+		{
+			name:   "returns error for bad name",
+			format: "%{9lives:s}",
+			str:    "lola",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 3: verb '%%{9lives:s}' has bad name '9lives'", ErrBadArg),
+		},
+		{
+			name:   "returns error for duplicate name",
+			format: "%{id:d}-%{id:d}",
+			str:    "1-2",
+			targetPtrs: []interface{}{
+				&intVal1,
+				&intVal2,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 11: verb '%%{id:d}' has duplicate name 'id'", ErrBadArg),
+		},
+		{
+			name:   "returns error for unsupported long form type",
+			format: "id=%{num}",
+			str:    "id=1",
+			targetPtrs: []interface{}{
+				&intVal1,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 6: verb '%%{num}' has unsupported type 'num'", ErrBadArg),
+		},
+		{
+			name:   "returns error for bad time layout",
+			format: "at %{time:YYYY-MM-DD}",
+			str:    "at 2023-10-11",
+			targetPtrs: []interface{}{
+				&timeVal1,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 11: verb '%%{time:YYYY-MM-DD}' has layout 'YYYY-MM-DD' without elements of the reference time", ErrBadArg),
+		},
+		{
+			name:   "handles explicit argument indexes",
+			format: "%[2]s is %[1]d years old",
+			str:    "lola is 3 years old",
+			targetPtrs: []interface{}{
+				&intVal1,
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, 3, intVal1)
+				assert.Equal(t, "lola", stringVal1)
+			},
+		},
+		{
+			name:   "handles verbs following explicit argument indexes",
+			format: "%[2]s %d %[1]d",
+			str:    "lola 3 7",
+			targetPtrs: []interface{}{
+				&intVal1,
+				&stringVal1,
+				&intVal2,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "lola", stringVal1)
+				assert.Equal(t, 3, intVal2)
+				assert.Equal(t, 7, intVal1)
+			},
+		},
+		{
+			name:   "handles explicit argument indexes on long form verbs",
+			format: "%[2]{key:s}=%[1]-5{d}",
+			str:    "port=8080",
+			targetPtrs: []interface{}{
+				&intVal1,
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, 8080, intVal1)
+				assert.Equal(t, "port", stringVal1)
+			},
+		},
+		{
+			name:   "handles repeated argument index with equal captures",
+			format: "%[1]s/%[1]s",
+			str:    "lola/lola",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "lola", stringVal1)
+			},
+		},
+		{
+			name:   "returns error for repeated argument index with different captures",
+			format: "%[1]s/%[1]s",
+			str:    "lola/cat",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: 'cat' captured for verb '%[1]s' differs from 'lola' captured for verb '%[1]s'",
+		},
+		{
+			name:   "returns error for unused argument index",
+			format: "%[2]s, %s",
+			str:    "a, b",
+			targetPtrs: []interface{}{
+				&stringVal1,
+				&stringVal2,
+				&stringVal3,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: argument index [1] is used by no verb", ErrBadArg),
+		},
+		{
+			name:   "returns error for bad argument index",
+			format: "id %[0]d",
+			str:    "id 1",
+			targetPtrs: []interface{}{
+				&intVal1,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 5: bad argument index '[0]'; must be a number from 1", ErrBadArg),
+		},
+		{
+			name:   "returns error for unclosed argument index",
+			format: "id %[1d",
+			str:    "id 1",
+			targetPtrs: []interface{}{
+				&intVal1,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 5: missing ']' to close argument index '[1d'", ErrBadArg),
+		},
+		{
+			name:   "returns error for target count not matching argument indexes",
+			format: "%[1]s/%[1]s",
+			str:    "lola/lola",
+			targetPtrs: []interface{}{
+				&stringVal1,
+				&stringVal2,
+			},
+			shouldError:   true,
+			expectedError: "got 2 'targetPtrs' for 1 argument indexes; count must match",
+		},
+		{
+			name:   "handles back-reference by name",
+			format: "BEGIN %{txn:s} ... END %{=txn}",
+			str:    "BEGIN a1 ... END b2 BEGIN b2 ... END b2",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "b2", stringVal1)
+			},
+		},
+		{
+			name:   "handles back-reference by argument index",
+			format: "%d + %{=1} = %d",
+			str:    "2 + 2 = 4",
+			targetPtrs: []interface{}{
+				&intVal1,
+				&intVal2,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, 2, intVal1)
+				assert.Equal(t, 4, intVal2)
+			},
+		},
+		{
+			name:   "discards candidates with unequal repeated argument index captures",
+			format: "<%[1]s>%[1]s",
+			str:    "<a>b <b>b",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, "b", stringVal1)
+			},
+		},
+		{
+			name:   "returns error for back-reference not matching",
+			format: "BEGIN %{txn:s} ... END %{=txn}",
+			str:    "BEGIN a1 ... END b2",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: expected 'a1' captured for verb '%{txn:s}' at start of 'b2' for back-reference '%{=txn}'",
+		},
+		{
+			name:   "returns error for back-reference to a capture its text begins with",
+			format: "BEGIN %s END %{=1}",
+			str:    "BEGIN tx1 END tx12",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: expected 'tx1' captured for verb '%s' at start of 'tx12' for back-reference '%{=1}'",
+		},
+		{
+			name:   "returns error for back-reference to a capture its text begins with before a segment",
+			format: "BEGIN %s END %{=1} done",
+			str:    "BEGIN tx1 END tx12 done",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: expected 'tx1' captured for verb '%s' at start of 'tx12' for back-reference '%{=1}'",
+		},
+		{
+			name:   "returns error for back-reference to no earlier verb",
+			format: "%{=txn} %{txn:s}",
+			str:    "a a",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 4: verb '%%{=txn}' references no earlier verb 'txn'", ErrBadArg),
+		},
+		{
+			name:   "returns error for back-reference with argument index",
+			format: "%s %[1]{=1}",
+			str:    "a a",
+			targetPtrs: []interface{}{
+				&stringVal1,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 4: back-reference '%%[1]{=1}' takes no argument index", ErrBadArg),
+		},
+		{
+			name:   "handles repeated strings",
+			format: "tags=%{s,*};",
+			str:    "tags=a, b,c;",
+			targetPtrs: []interface{}{
+				&stringsVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, []string{"a", "b", "c"}, stringsVal1)
+			},
+		},
+		{
+			name:   "handles repeated integers separated by spaces",
+			format: "ports: %{d +} and %d",
+			str:    "ports: 80 443 8080 and 1",
+			targetPtrs: []interface{}{
+				&intsVal1,
+				&intVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, []int{80, 443, 8080}, intsVal1)
+				assert.Equal(t, 1, intVal1)
+			},
+		},
+		{
+			name:   "handles no repeated values",
+			format: "tags=%{s,*};",
+			str:    "tags=;",
+			targetPtrs: []interface{}{
+				&stringsVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, []string{}, stringsVal1)
+			},
+		},
+		{
+			name:   "handles repeated values up to max count",
+			format: "[%{d,1..3}]",
+			str:    "[1,2,3]",
+			targetPtrs: []interface{}{
+				&intsVal1,
+			},
+			assertResult: func(t *testing.T) {
+				assert.Equal(t, []int{1, 2, 3}, intsVal1)
+			},
+		},
+		{
+			name:   "returns error for more repeated values than max count",
+			format: "[%{d,1..2}]",
+			str:    "[1,2,3]",
+			targetPtrs: []interface{}{
+				&intsVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: found more than 2 values in '1,2,3'",
+		},
+		{
+			name:   "returns error for repeated value outside constraints",
+			format: "%{d,+:1..10}",
+			str:    "11,3",
+			targetPtrs: []interface{}{
+				&intsVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: value 1: '11' captured for verb '%{d,+:1..10}' violates constraint '1..10'",
+		},
+		{
+			name:   "returns error for fewer repeated values than min count",
+			format: "[%{d,2..3}]",
+			str:    "[1]",
+			targetPtrs: []interface{}{
+				&intsVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: found 1 values, fewer than 2, in '1'",
+		},
+		{
+			name:   "returns error for repetition without count",
+			format: "%{d,}",
+			str:    "1",
+			targetPtrs: []interface{}{
+				&intsVal1,
+			},
+			shouldError:   true,
+			expectedError: fmt.Sprintf("parsing 'format': %s: at column 4: verb '%%{d,}' has repetition ',' without a count of '*', '+', 'n' or 'min..max'", ErrBadArg),
+		},
+		{
+			name:   "returns error for repeated verb with non-slice target",
+			format: "%{d,*}",
+			str:    "1,2",
+			targetPtrs: []interface{}{
+				&intVal1,
+			},
+			shouldError:   true,
+			expectedError: "assigning values to 'targetPtrs': at index 0: expected slice pointer as target, got *int",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// _, err := fmt.Sscanf(tc.str, tc.format, tc.targetPtrs...)
+			err := ScanString(tc.str, tc.format, tc.targetPtrs...)
+			if tc.shouldError {
+				assert.Error(t, err)
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			tc.assertResult(t)
+
+			assertRoundTrip(t, tc.format, tc.targetPtrs)
+		})
+	}
+}
+
+// Renders the values 'targetPtrs' point to per 'format', then checks that scanning the result assigns equal values.
+func assertRoundTrip(t *testing.T, format string, targetPtrs []interface{}) {
+	t.Helper()
+
+	scanner, err := NewScanner(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	str, err := scanner.Render(targetPtrs...)
+	if !assert.NoError(t, err, "rendering") {
+		return
+	}
+
+	rescanned := make([]interface{}, len(targetPtrs))
+	for i, ptr := range targetPtrs {
+		rescanned[i] = reflect.New(reflect.TypeOf(ptr).Elem()).Interface()
+	}
+
+	err = scanner.ScanString(str, rescanned...)
+	if !assert.NoError(t, err, "scanning rendered '%s'", str) {
+		return
+	}
+
+	for i := range targetPtrs {
+		assert.Equal(t, reflect.ValueOf(targetPtrs[i]).Elem().Interface(), reflect.ValueOf(rescanned[i]).Elem().Interface(), "rescanning rendered '%s'", str)
+	}
+}
+
+const story = `Once upon a time, there was a cat named Lola. 
+She liked to curl up in our yard. 
+Her favorite color is yellow and her favorite number is 3, but that's silly, because she's a cat.`
+
+func BenchmarkScanString(b *testing.B) {
+	var number string
+	var three int
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// err := ScanString(story, "and her %s is %d,", &favoriteNumber, &three)
+		// if err != nil {
+		// 	b.Fatal("got unexpected error", err)
+		// }
+		// _, err := fmt.Sscanf("my favorite number is 3", "my favorite %s is %d", &number, &three)
+		err := ScanString("my favorite number is 3", "my favorite %s is %d", &number, &three) // Go's is faster, fewer allocations. Dig into how.
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.StopTimer()
+
+	assert.Equal(b, "number", number)
+	assert.Equal(b, 3, three)
+}
+
+func TestFormatting(t *testing.T) {
+	t.SkipNow()
+
+	if false {
+		t.FailNow()
+	}
+	fmt.Printf("%0120.2f\n", 10.123)
+}
+
+func TestVerbs(t *testing.T) {
+	t.SkipNow()
+
+	if false {
+		t.FailNow()
+	}
+
+	format := "%5s %9.2d %-8s"
+
+	p, err := newPattern(format, options{})
+	if err != nil {
+		t.Error(err)
+	}
+
+	fmt.Printf("p.segments = %#v\n", p.segments)
+	fmt.Printf("p.verbs = %#v\n", p.verbs)
+}
+
+func TestGoFmtScanf(t *testing.T) {
+	var i int
+	var f float32
+	var s string
+
+	_, err := fmt.Sscanf("12345678", "%1d%3f%4s", &i, &f, &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, i)
+	assert.Equal(t, float32(234), f)
+	assert.Equal(t, "5678", s)
+
+	_, err = fmt.Sscanf("12345foo", "%d%s", &i, &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 12345, i)
+	assert.Equal(t, "foo", s)
+}
+
+func TestIntConvert(t *testing.T) {
+	i, err := strconv.Atoi("-1000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, -1000000, i)
+}
+
+func TestScanner_ScanString(t *testing.T) {
+	scanner, err := NewScanner("%5s%d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var str string
+	var i int
+
+	err = scanner.ScanString("f00 22", &str, &i)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "f00", str)
+	assert.Equal(t, 22, i)
+
+	err = scanner.ScanString("foo221000", &str, &i)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "foo22", str)
+	assert.Equal(t, 1000, i)
+
+	err = scanner.ScanString("blue 42 set hut hut!", &str, &i)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "blue", str)
+	assert.Equal(t, 42, i)
+}
+
+func TestScanString_ConstraintError(t *testing.T) {
+	var user string
+
+	err := ScanString("user: x", "user: %{s:len=3..16}", &user)
+
+	var constraintErr *ConstraintError
+	if assert.True(t, errors.As(err, &constraintErr)) {
+		assert.Equal(t, "%{s:len=3..16}", constraintErr.Verb)
+		assert.Equal(t, "x", constraintErr.Text)
+		assert.Equal(t, "len=3..16", constraintErr.Constraint)
+	}
+
+	// With multiple candidates all violating constraints, the first violation is returned.
+	err = ScanString("a-b-c", "%{s:len=4..}-%s", &user, &user)
+
+	constraintErr = nil
+	if assert.True(t, errors.As(err, &constraintErr)) {
+		assert.Equal(t, "a", constraintErr.Text)
+	}
+
+	// The target is left as is.
+	port := 8080
+	err = ScanString("port 70000", "port %{d:1..65535}", &port)
+
+	constraintErr = nil
+	assert.True(t, errors.As(err, &constraintErr))
+	assert.Equal(t, 8080, port)
+}
+
+func TestScanner_WithBoolWords(t *testing.T) {
+	scanner, err := NewScanner("debug=%t verbose=%t%s", WithBoolWords(
+		[]string{"yes", "on", "enabled", "Y"},
+		[]string{"no", "off", "disabled", "N"},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var debug, verbose bool
+	var rest string
+
+	err = scanner.ScanString("debug=YES verbose=Off;", &debug, &verbose, &rest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, true, debug)
+	assert.Equal(t, false, verbose)
+	assert.Equal(t, ";", rest)
+
+	err = scanner.ScanString("debug=n verbose=enabled!", &debug, &verbose, &rest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, false, debug)
+	assert.Equal(t, true, verbose)
+	assert.Equal(t, "!", rest)
+
+	err = scanner.ScanString("debug=true verbose=nope", &debug, &verbose, &rest)
+	assert.EqualError(t, err, "assigning values to 'targetPtrs': at index 0: expected leading bool word, got 'true'")
+
+	_, err = NewScanner("%t", WithBoolWords([]string{"on"}, []string{"ON"}))
+	assert.EqualError(t, err, fmt.Sprintf("initializing new scanner from 'opts': %s: bool word 'ON' given for both true and false", ErrBadArg))
+}
+
+func TestScanner_WithNormalizerAndValidator(t *testing.T) {
+	errReserved := errors.New("reserved port")
+
+	scanner, err := NewScanner("user=%s bytes=%d port=%d",
+		WithNormalizer(0, strings.ToLower),
+		WithNormalizer(1, func(s string) string {
+			return strings.ReplaceAll(s, ",", "")
+		}),
+		WithValidator(2, func(v interface{}) error {
+			if v.(int) < 1024 {
+				return errReserved
+			}
+
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var user string
+	var size int64
+	var port int
+
+	err = scanner.ScanString("user=Lola bytes=1,234,567 port=8080", &user, &size, &port)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "lola", user)
+	assert.Equal(t, int64(1234567), size)
+	assert.Equal(t, 8080, port)
+
+	err = scanner.ScanString("user=Lola bytes=1 port=80", &user, &size, &port)
+	assert.True(t, errors.Is(err, errReserved))
+	assert.EqualError(t, err, "assigning values to 'targetPtrs': at index 2: validating '80' captured for verb '%d': reserved port")
+
+	_, err = NewScanner("%s", WithValidator(1, func(interface{}) error { return nil }))
+	assert.EqualError(t, err, fmt.Sprintf("initializing new scanner from 'format': %s: validator registered for verb index 1 of 1 verbs", ErrBadArg))
+
+	scanner, err = NewScanner("user=%{user:s} port=%{port:d}",
+		WithNormalizerFor("user", strings.ToLower),
+		WithValidatorFor("port", func(v interface{}) error {
+			if v.(int) < 1024 {
+				return errReserved
+			}
 
-	b.ResetTimer()
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	for i := 0; i < b.N; i++ {
-		// err := ScanString(story, "and her %s is %d,", &favoriteNumber, &three)
-		// if err != nil {
-		// 	b.Fatal("got unexpected error", err)
-		// }
-		// _, err := fmt.Sscanf("my favorite number is 3", "my favorite %s is %d", &number, &three)
-		err := ScanString("my favorite number is 3", "my favorite %s is %d", &number, &three) // Go's is faster, fewer allocations. Dig into how.
-		if err != nil {
-			b.Fatal(err)
-		}
+	err = scanner.ScanString("user=Lola port=8080", &user, &port)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	b.StopTimer()
+	assert.Equal(t, "lola", user)
+	assert.Equal(t, 8080, port)
 
-	assert.Equal(b, "number", number)
-	assert.Equal(b, 3, three)
+	err = scanner.ScanString("user=Lola port=80", &user, &port)
+	assert.EqualError(t, err, "assigning values to 'targetPtrs': at index 1: validating '80' captured for verb '%{port:d}': reserved port")
+
+	_, err = NewScanner("%{user:s}", WithNormalizerFor("name", strings.ToLower))
+	assert.EqualError(t, err, fmt.Sprintf("initializing new scanner from 'format': %s: normalizer registered for verb name 'name' not in the pattern", ErrBadArg))
+
+	_, err = NewScanner("%{user:s}", WithValidatorFor("port", func(interface{}) error { return nil }))
+	assert.EqualError(t, err, fmt.Sprintf("initializing new scanner from 'format': %s: validator registered for verb name 'port' not in the pattern", ErrBadArg))
 }
 
-func TestFormatting(t *testing.T) {
-	t.SkipNow()
+func TestScanner_WithDefault(t *testing.T) {
+	scanner, err := NewScanner("%s,%d,%s", WithDefault(1, "0"))
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	if false {
-		t.FailNow()
+	var name, city string
+	var age int
+
+	err = scanner.ScanString("lola,,chicago", &name, &age, &city)
+	if err != nil {
+		t.Fatal(err)
 	}
-	fmt.Printf("%0120.2f\n", 10.123)
+
+	assert.Equal(t, "lola", name)
+	assert.Equal(t, 0, age)
+	assert.Equal(t, "chicago", city)
+
+	err = scanner.ScanString("lola,3,chicago", &name, &age, &city)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 3, age)
+
+	_, err = NewScanner("%d", WithDefault(0, "-"))
+	assert.EqualError(t, err, fmt.Sprintf("initializing new scanner from 'format': %s: bad default '-' for verb '%%d': error converting '-' to integer: strconv.ParseInt: parsing \"-\": invalid syntax", ErrBadArg))
 }
 
-func TestVerbs(t *testing.T) {
-	t.SkipNow()
+func TestScanner_WithNullTokens(t *testing.T) {
+	scanner, err := NewScanner(`"%s %s" %d %d %s`, WithNullTokens("-", "N/A"))
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	if false {
-		t.FailNow()
+	var method, path, agent string
+	var status int
+	bytes := new(int)
+
+	err = scanner.ScanString(`"GET /index.html" 304 - N/A`, &method, &path, &status, &bytes, &agent)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	format := "%5s %9.2d %-8s"
+	assert.Equal(t, "GET", method)
+	assert.Equal(t, "/index.html", path)
+	assert.Equal(t, 304, status)
+	assert.Nil(t, bytes)
+	assert.Equal(t, "", agent)
 
-	p, err := newPattern(format)
+	err = scanner.ScanString(`"GET /index.html" 200 5120 curl`, &method, &path, &status, &bytes, &agent)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 
-	fmt.Printf("p.segments = %#v\n", p.segments)
-	fmt.Printf("p.verbs = %#v\n", p.verbs)
+	if assert.NotNil(t, bytes) {
+		assert.Equal(t, 5120, *bytes)
+	}
+
+	status = 200
+	err = scanner.ScanString(`"GET /index.html" - - curl`, &method, &path, &status, &bytes, &agent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 0, status)
 }
 
-func TestGoFmtScanf(t *testing.T) {
-	var i int
-	var f float32
-	var s string
+func TestScanner_WithVerbs(t *testing.T) {
+	hex := CustomVerb{
+		Match: func(str string) (int, error) {
+			n := strings.IndexFunc(str, hexRunes.excludes)
+			switch n {
+			case 0:
+				return 0, fmt.Errorf("expected leading hex digits, got '%s'", str)
+			case -1:
+				return len(str), nil
+			}
 
-	_, err := fmt.Sscanf("12345678", "%1d%3f%4s", &i, &f, &s)
+			return n, nil
+		},
+		Convert: func(text string, target interface{}) error {
+			pInt, ok := target.(*int64)
+			if !ok {
+				return fmt.Errorf("expected int64 pointer as target, got %T", target)
+			}
+
+			var err error
+			*pInt, err = strconv.ParseInt(text, 16, 64)
+			return err
+		},
+		New: func() interface{} { return new(int64) },
+	}
+
+	hostname := CustomVerb{
+		Match: func(str string) (int, error) {
+			n := strings.IndexFunc(str, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-'
+			})
+			if n < 0 {
+				n = len(str)
+			}
+
+			if n == 0 {
+				return 0, fmt.Errorf("expected leading hostname, got '%s'", str)
+			}
+
+			return n, nil
+		},
+		Convert: func(text string, target interface{}) error {
+			pStr, ok := target.(*string)
+			if !ok {
+				return fmt.Errorf("expected string pointer as target, got %T", target)
+			}
+
+			*pStr = text
+			return nil
+		},
+	}
+
+	team1 := NewVerbSet()
+	if err := team1.Register("x", hex); err != nil {
+		t.Fatal(err)
+	}
+
+	team2 := NewVerbSet()
+	if err := team2.Register("hostname", hostname); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner, err := NewScanner("%{hostname}:%d id=%x%s", WithVerbs(team1, team2))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	assert.Equal(t, 1, i)
-	assert.Equal(t, float32(234), f)
-	assert.Equal(t, "5678", s)
+	var host, rest string
+	var port int
+	var id int64
 
-	_, err = fmt.Sscanf("12345foo", "%d%s", &i, &s)
+	err = scanner.ScanString("api.example.com:8443 id=ff0Azz", &host, &port, &id, &rest)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	assert.Equal(t, 12345, i)
-	assert.Equal(t, "foo", s)
+	assert.Equal(t, "api.example.com", host)
+	assert.Equal(t, 8443, port)
+	assert.Equal(t, int64(0xff0a), id)
+	assert.Equal(t, "zz", rest)
+
+	// Custom verbs are scoped to the Scanners configured with them.
+	_, err = NewScanner("id=%x")
+	assert.EqualError(t, err, fmt.Sprintf("initializing new scanner from 'format': %s: unsupported verb '%%x'", ErrBadArg))
+
+	_, err = NewScanner("id=%x", WithVerbs(team1), WithVerb("x", hex))
+	assert.EqualError(t, err, fmt.Sprintf("initializing new scanner from 'opts': %s: custom verb 'x' given more than once", ErrBadArg))
+
+	err = team1.Register("d", hex)
+	assert.EqualError(t, err, fmt.Sprintf("%s: verb name 'd' shadows a built-in verb", ErrBadArg))
+
+	err = team1.Register("x", hex)
+	assert.EqualError(t, err, fmt.Sprintf("%s: verb 'x' already registered", ErrBadArg))
 }
 
-func TestIntConvert(t *testing.T) {
-	i, err := strconv.Atoi("-1000000")
+func TestScanMap(t *testing.T) {
+	values, err := ScanMap(
+		"lola@10.0.0.1 active=true age=3 at 2023-10-11 again lola",
+		"%{user:s}@%{ip:I} active=%{active:t} age=%{age:d} at %{day:time:2006-01-02} again %{=user}",
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	assert.Equal(t, -1000000, i)
+	assert.Equal(t, map[string]interface{}{
+		"user":   "lola",
+		"ip":     netip.MustParseAddr("10.0.0.1"),
+		"active": true,
+		"age":    int64(3),
+		"day":    time.Date(2023, 10, 11, 0, 0, 0, 0, time.UTC),
+	}, values)
+
+	_, err = ScanMap("lola is 3", "%{user:s} is %d")
+	assert.EqualError(t, err, fmt.Sprintf("%s: verb '%%d' has no name to map its capture to", ErrBadArg))
+
+	scanner, err := NewScanner("%{status:d} %{bytes:d}", WithNullTokens("-"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err = scanner.ScanMap("304 -")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, map[string]interface{}{"status": int64(304), "bytes": nil}, values)
+
+	_, err = scanner.ScanMap("304 lots")
+	assert.EqualError(t, err, "assigning values: at index 1: expected one or more leading numeric characters, got 'lots'")
 }
 
-func TestScanner_ScanString(t *testing.T) {
-	scanner, err := NewScanner("%5s%d")
+func TestScanValues(t *testing.T) {
+	values, err := ScanValues("lola is 3, true since 1.2.0", "%[2]s is %[1]d, %[3]t since %V")
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	var str string
-	var i int
+	assert.Equal(t, []interface{}{3, "lola", true, Version{Major: 1, Minor: 2}}, values)
 
-	err = scanner.ScanString("f00 22", &str, &i)
+	scanner, err := NewScanner("%d-%d", WithNullTokens("?"))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	assert.Equal(t, "f00", str)
-	assert.Equal(t, 22, i)
+	values, err = scanner.ScanValues("7-?")
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	err = scanner.ScanString("foo221000", &str, &i)
+	assert.Equal(t, []interface{}{7, nil}, values)
+
+	values, err = ScanValues("ports 80 443", "ports %{d +}")
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	assert.Equal(t, "foo22", str)
-	assert.Equal(t, 1000, i)
+	assert.Equal(t, []interface{}{[]int{80, 443}}, values)
 
-	err = scanner.ScanString("blue 42 set hut hut!", &str, &i)
+	_, err = ScanValues("", "%d")
+	assert.EqualError(t, err, fmt.Sprintf("%s: 'str' must not be empty", ErrBadArg))
+}
+
+type Endpoint struct {
+	Host string `unfmt:"host"`
+	Port *uint16
+}
+
+type request struct {
+	*Endpoint
+	Method string
+	Path   string `unfmt:"path"`
+	Status **int
+	Ignore string `unfmt:"-"`
+}
+
+func TestScanStruct(t *testing.T) {
+	var req request
+	err := ScanStruct(
+		"GET example.com:8080/index.html 200",
+		"%{method:s} %{host:s}:%{port:d}%{path:s} %{status:d}",
+		&req,
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	assert.Equal(t, "blue", str)
-	assert.Equal(t, 42, i)
+	assert.Equal(t, "GET", req.Method)
+	assert.Equal(t, "/index.html", req.Path)
+	if assert.NotNil(t, req.Endpoint) {
+		assert.Equal(t, "example.com", req.Host)
+		if assert.NotNil(t, req.Port) {
+			assert.Equal(t, uint16(8080), *req.Port)
+		}
+	}
+	if assert.NotNil(t, req.Status) && assert.NotNil(t, *req.Status) {
+		assert.Equal(t, 200, **req.Status)
+	}
+
+	err = ScanStruct("GET /", "%{method:s} %{ignore:s}", &req)
+	assert.EqualError(t, err, fmt.Sprintf("%s: no field of unfmt.request for verb named 'ignore'", ErrBadArg))
+
+	err = ScanStruct("GET /", "%{method:s} %s", &req)
+	assert.EqualError(t, err, fmt.Sprintf("%s: verb '%%s' has no name to map its capture to", ErrBadArg))
+
+	err = ScanStruct("GET /", "%{method:s} %{path:s}", req)
+	assert.EqualError(t, err, fmt.Sprintf("%s: expected non-nil struct pointer, got unfmt.request", ErrBadArg))
+
+	_, err = NewScanner("%{method:d} %{path:s}", WithStruct(request{}))
+	assert.EqualError(t, err, fmt.Sprintf("initializing new scanner from 'format': %s: verb '%%{method:d}' can't assign to field Method of type string: expected integer pointer as target, got *string", ErrBadArg))
+
+	_, err = NewScanner("%{method:s}", WithStruct(42))
+	assert.EqualError(t, err, fmt.Sprintf("initializing new scanner from 'opts': %s: WithStruct requires a struct or struct pointer, got int", ErrBadArg))
+
+	scanner, err := NewScanner("%{method:s} %{path:s}", WithStruct((*request)(nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = scanner.ScanStruct("POST /login", &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "POST", req.Method)
+	assert.Equal(t, "/login", req.Path)
+
+	err = scanner.ScanStruct("POST /login", &Endpoint{})
+	assert.EqualError(t, err, fmt.Sprintf("%s: expected unfmt.request pointer as 'v' per WithStruct, got *unfmt.Endpoint", ErrBadArg))
+
+	var hc healthCheck
+	scanner, err = NewScanner("up=%{up:t} ports=%{ports:d,2..3}", WithBoolWords([]string{"yes"}, []string{"no"}), WithStruct(hc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = scanner.ScanStruct("up=yes ports=80,443", &hc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, healthCheck{Up: true, Ports: []int{80, 443}}, hc)
+}
+
+type healthCheck struct {
+	Up    bool
+	Ports []int
+}
+
+type hostPort struct {
+	_    struct{} `unfmt:"%{host:s}:%{port:d}"`
+	Host string
+	Port int
+}
+
+type logLine struct {
+	Level  string
+	Remote *hostPort
+	Took   time.Duration
+}
+
+func (logLine) UnfmtFormat() string {
+	return "[%{level:enum:INFO|WARN}] from %{remote:s} in %{took:D}"
+}
+
+func TestUnmarshal(t *testing.T) {
+	var line logLine
+	err := Unmarshal("[WARN] from 10.0.0.1:8080 in 1.5s", &line)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "WARN", line.Level)
+	assert.Equal(t, 1500*time.Millisecond, line.Took)
+	assert.Equal(t, &hostPort{Host: "10.0.0.1", Port: 8080}, line.Remote)
+
+	err = Unmarshal("[INFO] from localhost in 2s", &line)
+	assert.EqualError(t, err, "scanning field Remote: capturing from 'str': 'str' does not match 'format': could not find substring ':' in 'localhost'")
+
+	err = Unmarshal("lola", &Endpoint{})
+	assert.EqualError(t, err, fmt.Sprintf("%s: unfmt.Endpoint declares no format", ErrBadArg))
+
+	var p post
+	err = Unmarshal("hello [a,b]", &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, post{Title: "hello", Tags: tagList{"a", "b"}}, p)
+
+	str, err := Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "hello [a,b]", str)
+
+	err = Unmarshal("<a>", &wrapper{})
+	assert.EqualError(t, err, fmt.Sprintf("%s: field X captured all of '<a>', so scanning it per the format of *unfmt.wrapper would never end", ErrBadArg))
+}
+
+// Not a struct, so its format is ignored and it's assigned to as any slice.
+type tagList []string
+
+func (tagList) UnfmtFormat() string {
+	return "%{tags:s,*}"
+}
+
+type post struct {
+	_     struct{} `unfmt:"%{title:s} [%{tags:s,+}]"`
+	Title string
+	Tags  tagList
+}
+
+type wrapper struct {
+	_ struct{} `unfmt:"%{x:s}"`
+	X *wrapper
+}
+
+func TestScanner_Render(t *testing.T) {
+	scanner, err := NewScanner("%[2]{user:s} paid %[1].2{m} on %[3]{day:time:2006-01-02} (%{d=0} items, %d%%)", WithNullTokens("-"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2023, 10, 11, 0, 0, 0, 0, time.UTC)
+	str, err := scanner.Render(123450, "lola", day, nil, (*int)(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "lola paid 1234.50 on 2023-10-11 ( items, -%)", str)
+
+	_, err = scanner.Render(1, "lola")
+	assert.EqualError(t, err, "got 2 'values' for 5 verbs; count must match")
+
+	_, err = scanner.Render(true, "lola", day, 1, 2)
+	assert.EqualError(t, err, "at index 0: expected integer, big.Rat or encoding.TextMarshaler value, got bool")
+
+	scanner, err = NewScanner("%d %s %3d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = scanner.Render("abc", "a", 1)
+	assert.EqualError(t, err, "at index 0: 'abc' can't be scanned by verb '%d': expected one or more leading numeric characters, got 'abc'")
+
+	_, err = scanner.Render("12a", "a", 1)
+	assert.EqualError(t, err, "at index 0: only '12' of '12a' would be scanned by verb '%d'")
+
+	_, err = scanner.Render(1, "a b", 1)
+	assert.EqualError(t, err, "at index 1: 'a b' contains a space, at which verb '%s' would stop scanning")
+
+	_, err = scanner.Render(1, "a", 12345)
+	assert.EqualError(t, err, "at index 2: '12345' exceeds max width 3 of verb '%3d'")
+
+	// Text each verb accepts on its own may still not scan back from the whole.
+	testCases := []struct {
+		format        string
+		values        []interface{}
+		expectedError string
+	}{
+		{
+			format:        "%d%s",
+			values:        []interface{}{1, "2"},
+			expectedError: "rendered '12' doesn't scan back: at index 1: all of substring '12' consumed by prior adjacent verb(s), none left for next verb '%s'",
+		},
+		{
+			format:        "tags=%{s,*}",
+			values:        []interface{}{[]string{"a,b", "c"}},
+			expectedError: "at index 0: value 1: 'a,b' contains separator ',' of verb '%{s,*}'",
+		},
+		{
+			format:        "a%sb",
+			values:        []interface{}{""},
+			expectedError: "rendered 'ab' doesn't scan back: 'str' does not match 'format'",
+		},
+		{
+			format:        "%s-%s",
+			values:        []interface{}{"a-b", "c"},
+			expectedError: "rendered 'a-b-c' doesn't scan back: 'str' matches 'format' more than once",
+		},
+		{
+			format:        "%{s=none};",
+			values:        []interface{}{""},
+			expectedError: "at index 0: rendered ';' scans back 'none' for verb '%{s=none}' rather than ''",
+		},
+		{
+			format:        "%s;",
+			values:        []interface{}{"-"},
+			expectedError: "at index 0: '-' rendered for verb '%s' scans back as a null token",
+		},
+	}
+
+	for _, tc := range testCases {
+		scanner, err := NewScanner(tc.format, WithNullTokens("-"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = scanner.Render(tc.values...)
+		assert.EqualError(t, err, tc.expectedError, tc.format)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	line := logLine{
+		Level:  "INFO",
+		Remote: &hostPort{Host: "10.0.0.1", Port: 8080},
+		Took:   90 * time.Second,
+	}
+
+	str, err := Marshal(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "[INFO] from 10.0.0.1:8080 in 1m30s", str)
+
+	var unmarshaled logLine
+	err = Unmarshal(str, &unmarshaled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, line, unmarshaled)
+
+	_, err = Marshal(logLine{Level: "INFO"})
+	assert.EqualError(t, err, "at index 1: nil value for verb '%{remote:s}' without a default or null tokens")
+
+	_, err = Marshal(Endpoint{})
+	assert.EqualError(t, err, fmt.Sprintf("%s: unfmt.Endpoint declares no format", ErrBadArg))
 }
@@ -0,0 +1,231 @@
+package unfmt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const semverIdentifierRunes runes = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ-"
+
+// Version is a semantic version as captured by the '%V' verb, per https://semver.org.
+type Version struct {
+	Major, Minor, Patch uint64
+
+	// Prerelease holds the dot-separated identifiers after a '-', if any.
+	Prerelease []string
+
+	// Build holds the dot-separated build metadata identifiers after a '+', if any.
+	Build []string
+}
+
+// String formats the Version without a 'v' prefix, e.g. '1.24.3-rc.1+build.5'.
+func (v Version) String() string {
+	str := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+
+	if len(v.Prerelease) > 0 {
+		str += "-" + strings.Join(v.Prerelease, ".")
+	}
+
+	if len(v.Build) > 0 {
+		str += "+" + strings.Join(v.Build, ".")
+	}
+
+	return str
+}
+
+/*
+Compare returns -1, 0 or 1 as v has lower, equal or higher precedence than 'other'.
+
+Major, minor and patch are compared numerically, in that order. A version with
+prerelease identifiers precedes the same version without, otherwise prerelease
+identifiers are compared one by one, numerically if both are numeric and lexically
+if not, with numeric identifiers preceding others. Build metadata is ignored.
+*/
+func (v Version) Compare(other Version) int {
+	if c := compareUint(v.Major, other.Major); c != 0 {
+		return c
+	}
+
+	if c := compareUint(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+
+	if c := compareUint(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := comparePrereleaseIdentifiers(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(other.Prerelease)))
+}
+
+// Less reports whether v has lower precedence than 'other'.
+func (v Version) Less(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+func comparePrereleaseIdentifiers(a, b string) int {
+	aNum, aErr := strconv.ParseUint(a, 10, 64)
+	bNum, bErr := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareUint(aNum, bNum)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+/*
+Assigns a semantic version with optional 'v' prefix, e.g. 'v1.24.3-rc.1+build.5',
+to a Version or validated string target. Exactly as many characters of 'str' as form
+the version are evaluated, so that the verb can be adjacent to others.
+*/
+func assignVersion(str string, target interface{}, _ verb) (int, error) {
+	n := semverSpan(str)
+	if n == 0 {
+		return 0, fmt.Errorf("expected leading semantic version, got '%s'", str)
+	}
+
+	version, err := parseVersion(str[:n])
+	if err != nil {
+		return 0, fmt.Errorf("error converting '%s' to semantic version: %w", str[:n], err)
+	}
+
+	switch v := target.(type) {
+	case *Version:
+		*v = version
+	case *string:
+		*v = str[:n]
+	default:
+		return 0, fmt.Errorf("expected Version or string pointer as target, got %T", target)
+	}
+
+	return n, nil
+}
+
+// Returns the length of the semantic version at the start of 'str'.
+func semverSpan(str string) int {
+	var i int
+	if i < len(str) && str[i] == 'v' {
+		i++
+	}
+
+	for part := 0; part < 3; part++ {
+		if part > 0 {
+			if i == len(str) || str[i] != '.' {
+				return 0
+			}
+
+			i++
+		}
+
+		n := runSpan(str[i:], digitRunes)
+		if n == 0 || n > 1 && str[i] == '0' {
+			return 0
+		}
+
+		i += n
+	}
+
+	for _, separator := range []byte{'-', '+'} {
+		if i == len(str) || str[i] != separator {
+			continue
+		}
+
+		n := semverIdentifiersSpan(str[i+1:])
+		if n == 0 {
+			break
+		}
+
+		i += 1 + n
+	}
+
+	return i
+}
+
+// Returns the length of the dot-separated identifiers at the start of 'str'.
+func semverIdentifiersSpan(str string) int {
+	var end int
+	for i := 0; i < len(str); {
+		n := runSpan(str[i:], semverIdentifierRunes)
+		if n == 0 {
+			break
+		}
+
+		i += n
+		end = i
+
+		if i == len(str) || str[i] != '.' {
+			break
+		}
+
+		i++
+	}
+
+	return end
+}
+
+// Parses a semantic version as found by semverSpan.
+func parseVersion(str string) (Version, error) {
+	var v Version
+
+	str = strings.TrimPrefix(str, "v")
+
+	if i := strings.IndexByte(str, '+'); i >= 0 {
+		v.Build = strings.Split(str[i+1:], ".")
+		str = str[:i]
+	}
+
+	if i := strings.IndexByte(str, '-'); i >= 0 {
+		v.Prerelease = strings.Split(str[i+1:], ".")
+		str = str[:i]
+
+		for _, identifier := range v.Prerelease {
+			if len(identifier) > 1 && identifier[0] == '0' && runSpan(identifier, digitRunes) == len(identifier) {
+				return v, fmt.Errorf("numeric prerelease identifier '%s' has leading zero", identifier)
+			}
+		}
+	}
+
+	parts := strings.Split(str, ".")
+	for i, pNum := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		num, err := strconv.ParseUint(parts[i], 10, 64)
+		if err != nil {
+			return v, err
+		}
+
+		*pNum = num
+	}
+
+	return v, nil
+}
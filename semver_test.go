@@ -0,0 +1,50 @@
+package unfmt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion_Compare(t *testing.T) {
+	// Ordered by ascending precedence, per https://semver.org/#spec-item-11.
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.2.0",
+		"2.0.0",
+	}
+
+	versions := make([]Version, len(ordered))
+	for i, str := range ordered {
+		err := ScanString(str, "%V", &versions[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, str, versions[i].String())
+	}
+
+	for i := range versions {
+		for j := range versions {
+			expected := compareUint(uint64(i), uint64(j))
+			assert.Equal(t, expected, versions[i].Compare(versions[j]), "%s vs %s", versions[i], versions[j])
+			assert.Equal(t, expected < 0, versions[i].Less(versions[j]), "%s vs %s", versions[i], versions[j])
+		}
+	}
+
+	var withBuild Version
+	err := ScanString("v1.0.0+build.5", "%V", &withBuild)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 0, withBuild.Compare(versions[7]))
+}
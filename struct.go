@@ -0,0 +1,300 @@
+package unfmt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Text each builtin verb accepts in full, to check that a struct field's type is one the verb can assign to.
+var probeTexts = map[string]string{
+	verbInt:      "1",
+	verbString:   "s",
+	verbEpoch:    "0",
+	verbDuration: "1s",
+	verbIP:       "192.0.2.1",
+	verbPrefix:   "192.0.2.0/24",
+	verbAddrPort: "192.0.2.1:80",
+	verbMAC:      "00:00:5e:00:53:01",
+	verbURL:      "https://example.com",
+	verbUUID:     "00000000-0000-0000-0000-000000000000",
+	verbVersion:  "1.0.0",
+	verbQuantity: "1",
+	verbDecimal:  "1",
+	verbColor:    "#000",
+}
+
+// A struct field that a named verb assigns to, located by its index sequence as for reflect.Value.FieldByIndex.
+type structField struct {
+	name   string
+	tagged bool
+	index  []int
+	typ    reflect.Type
+
+	// Whether the field's type declares its own format, per Unmarshal, to scan its capture with in turn.
+	nested bool
+}
+
+/*
+Returns the exported fields of struct type 't' that verbs may assign to,
+named by their 'unfmt' tags or else their field names. A field tagged '-'
+is skipped. As in encoding/json, the fields of an untagged embedded struct,
+or pointer to one, are promoted, after the fields of 't' itself so that those
+take precedence.
+*/
+func structFields(t reflect.Type, index []int) []structField {
+	var fields, promoted []structField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("unfmt")
+		if tag == "-" {
+			continue
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		if f.Anonymous && tag == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				// An unexported embedded pointer can't be allocated through.
+				if f.PkgPath != "" {
+					continue
+				}
+
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				promoted = append(promoted, structFields(ft, fieldIndex)...)
+				continue
+			}
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		field := structField{name: f.Name, index: fieldIndex, typ: f.Type}
+		if tag != "" {
+			field.name, field.tagged = tag, true
+		}
+
+		fields = append(fields, field)
+	}
+
+	return append(fields, promoted...)
+}
+
+// Returns the field for 'name', matching tags exactly before untagged field names regardless of case.
+func fieldByName(fields []structField, name string) (structField, bool) {
+	for _, f := range fields {
+		if f.tagged && f.name == name {
+			return f, true
+		}
+	}
+
+	for _, f := range fields {
+		if !f.tagged && strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+
+	return structField{}, false
+}
+
+/*
+Returns the field of struct type 't' that each of the pattern's argument indexes
+assigns to by name, or ErrBadArg if a verb has no name, no field by its name, or
+a field of a type it can't assign to. Only builtin verbs are checked for the latter.
+A verb for a field whose type declares its own format must be able to assign to
+a string, as the text it captures is scanned in turn.
+*/
+func (p pattern) bindStruct(t reflect.Type) ([]structField, error) {
+	names, err := p.targetNames()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := structFields(t, nil)
+
+	bound := make([]structField, len(names))
+	for i, name := range names {
+		f, ok := fieldByName(fields, name)
+		if !ok {
+			return nil, fmt.Errorf("%w: no field of %s for verb named '%s'", ErrBadArg, t, name)
+		}
+
+		bound[i] = f
+	}
+
+	for _, v := range p.verbs {
+		if v.backRef {
+			continue
+		}
+
+		f := bound[v.argIndex]
+
+		target := reflect.New(f.typ).Interface()
+		if _, ok := formatOf(f.typ); ok {
+			bound[v.argIndex].nested = true
+			target = new(string)
+		}
+
+		err = p.probe(v, target)
+		if err != nil {
+			return nil, fmt.Errorf("%w: verb '%s' can't assign to field %s of type %s: %s", ErrBadArg, v, f.name, f.typ, err)
+		}
+	}
+
+	return bound, nil
+}
+
+/*
+Reports any error assigning text 'v' accepts to 'target', unless 'v' is a custom verb.
+The text is per the verb's configuration, e.g. its bool words, enum values, time
+layout or, for a repeated verb, its min count of values.
+*/
+func (p pattern) probe(v verb, target interface{}) error {
+	elem := v
+	if v.repeat {
+		elem = v.elem()
+	}
+
+	text, ok := probeTexts[elem.value]
+
+	switch elem.value {
+	case verbBool:
+		var err error
+		text, err = p.renderFuncs[verbBool](true, elem)
+		if err != nil {
+			return err
+		}
+
+		ok = true
+	case verbEnum:
+		text, ok = elem.enumValues()[0], true
+	case verbTime:
+		text, ok = time.Unix(0, 0).UTC().Format(elem.timeLayout()), true
+	}
+
+	if !ok {
+		return nil
+	}
+
+	if v.repeat {
+		count := v.minCount
+		if count == 0 {
+			count = 1
+		}
+
+		texts := make([]string, count)
+		for i := range texts {
+			texts[i] = text
+		}
+
+		text = strings.Join(texts, v.separator)
+	}
+
+	_, err := p.assignTo(text, target, v)
+	return err
+}
+
+/*
+Returns a pointer to each bound field of the struct 'rv', allocating any nil
+embedded pointers on the way, or to a string to hold the capture for a nested field.
+*/
+func fieldTargets(rv reflect.Value, fields []structField) []interface{} {
+	targets := make([]interface{}, len(fields))
+	for i, f := range fields {
+		if f.nested {
+			targets[i] = new(string)
+			continue
+		}
+
+		targets[i] = fieldValue(rv, f.index).Addr().Interface()
+	}
+
+	return targets
+}
+
+// Returns a pointer to a new value of each bound field's type, or to a string for a nested field.
+func newFieldTargets(fields []structField) []interface{} {
+	targets := make([]interface{}, len(fields))
+	for i, f := range fields {
+		if f.nested {
+			targets[i] = new(string)
+			continue
+		}
+
+		targets[i] = reflect.New(f.typ).Interface()
+	}
+
+	return targets
+}
+
+// Returns the field of the struct 'rv' at 'index', allocating any nil embedded pointers on the way.
+func fieldValue(rv reflect.Value, index []int) reflect.Value {
+	v := rv
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v
+}
+
+/*
+Scans the text captured from 'str' for each nested field of the struct 'rv', among
+'values' by argument index, into the field per the format of its type. A field whose
+capture was a null token is left as is. A capture of all of 'str' is an error, since
+a type nested in itself would otherwise scan it without end.
+*/
+func unmarshalNested(str string, rv reflect.Value, fields []structField, values []interface{}) error {
+	for i, f := range fields {
+		if !f.nested || values[i] == nil {
+			continue
+		}
+
+		if values[i] == str {
+			return fmt.Errorf("%w: field %s captured all of '%s', so scanning it per the format of %s would never end", ErrBadArg, f.name, str, f.typ)
+		}
+
+		v := fieldValue(rv, f.index)
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		err := Unmarshal(values[i].(string), v.Addr().Interface())
+		if err != nil {
+			return fmt.Errorf("scanning field %s: %w", f.name, err)
+		}
+	}
+
+	return nil
+}
+
+// Returns the struct 'v' points to, or ErrBadArg if it doesn't point to one.
+func structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%w: expected non-nil struct pointer, got %T", ErrBadArg, v)
+	}
+
+	return rv.Elem(), nil
+}
@@ -0,0 +1,77 @@
+package unfmt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Formatted is implemented by struct types that declare the format Unmarshal scans their values from.
+type Formatted interface {
+	// UnfmtFormat returns a format with named verbs for the type's fields, e.g. '%{host:s}:%{port:d}'.
+	UnfmtFormat() string
+}
+
+/*
+Unmarshal captures values from 'str' and assigns them to the fields of the struct
+'v' points to, per the format its type declares, as does ScanStruct. The type
+declares its format by implementing Formatted, or else by the 'unfmt' tag of a
+blank field, as in:
+
+	type Endpoint struct {
+		_    struct{} `unfmt:"%{host:s}:%{port:d}"`
+		Host string
+		Port int
+	}
+
+A field whose type declares a format in turn is scanned from the text captured for
+it, so that a type owns its text representation wherever it's nested.
+*/
+func Unmarshal(str string, v interface{}) error {
+	rv, err := structValue(v)
+	if err != nil {
+		return err
+	}
+
+	format, ok := formatOf(rv.Type())
+	if !ok {
+		return fmt.Errorf("%w: %s declares no format", ErrBadArg, rv.Type())
+	}
+
+	pattern, err := newPattern(format, options{})
+	if err != nil {
+		return fmt.Errorf("parsing format of %s: %w", rv.Type(), err)
+	}
+
+	return Scanner{p: &pattern}.ScanStruct(str, v)
+}
+
+/*
+Returns the format struct type 't', or the struct type any pointers in 't' point
+to, declares for Unmarshal. Other types declare none, even if they implement Formatted.
+*/
+func formatOf(t reflect.Type) (string, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	if reflect.PtrTo(t).Implements(reflect.TypeOf((*Formatted)(nil)).Elem()) {
+		return reflect.New(t).Interface().(Formatted).UnfmtFormat(), true
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name != "_" {
+			continue
+		}
+
+		if format, ok := f.Tag.Lookup("unfmt"); ok && format != "" {
+			return format, true
+		}
+	}
+
+	return "", false
+}
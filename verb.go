@@ -1,23 +1,55 @@
 package unfmt
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type verb struct {
-	value rune
-	start int
-	flags []rune
+	// The verb's name, if given in long form as in '%{user:s}'.
+	name string
+
+	value   string
+	start   int
+	flags   []rune
+	options string
+
+	// The index of the target the verb assigns to, which may be shared with others.
+	argIndex int
+
+	// Whether the verb captures a number of values, as in '%{d,*}', separated by 'separator'.
+	// A negative max count means no max.
+	repeat             bool
+	separator          string
+	minCount, maxCount int
+
+	// Whether the verb is a back-reference, as in '%{=name}', to the verb at index 'ref' in the pattern.
+	backRef bool
+	ref     int
+
+	constraints []constraint
+
+	// Assigned in place of an empty capture, if the verb has one.
+	defaultValue string
+	hasDefault   bool
+
+	// The verb as written in the format, if in long form or with an argument index.
+	source string
 }
 
 func (v verb) String() string {
-	return "%" + string(v.flags) + string(v.value)
+	if v.source != "" {
+		return v.source
+	}
+
+	return "%" + string(v.flags) + v.value
 }
 
 func (v verb) len() int {
-	// Each supported verb and its accompanying '%' are single-byte UTF-8
-	// code points, hence 2.
-	return 2 + len(v.flags)
+	return len(v.String())
 }
 
 func (v verb) maxWidth() (int, bool) {
@@ -26,6 +58,11 @@ func (v verb) maxWidth() (int, bool) {
 
 	for i := range v.flags {
 		f := v.flags[i]
+		if f == '.' && v.takesPrecision() {
+			// Digits after '.' specify precision, not width, for verbs that take one.
+			break
+		}
+
 		if f >= '0' && f <= '9' {
 			taking = true
 			widthFlags += string(f)
@@ -48,7 +85,74 @@ func (v verb) maxWidth() (int, bool) {
 	return width, true
 }
 
+// Reports whether the verb reads digits after '.' as a precision rather than, as for others, a max width.
+func (v verb) takesPrecision() bool {
+	return v.value == verbEpoch || v.value == verbDecimal || v.value == verbQuantity
+}
+
+func (v verb) precision() (int, bool) {
+	var precisionFlags string
+	var taking bool
+
+	for i := range v.flags {
+		f := v.flags[i]
+		if taking {
+			if f < '0' || f > '9' {
+				break
+			}
+
+			precisionFlags += string(f)
+		}
+
+		if f == '.' {
+			taking = true
+		}
+	}
+
+	if !taking {
+		return 0, false
+	}
+
+	// As in package fmt, a '.' not followed by any digits means a precision of zero.
+	if len(precisionFlags) == 0 {
+		return 0, true
+	}
+
+	precision, err := strconv.Atoi(precisionFlags)
+	if err != nil {
+		return 0, false
+	}
+
+	return precision, true
+}
+
+func (v verb) hasFlag(flag rune) bool {
+	for _, f := range v.flags {
+		if f == flag {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (v verb) stopAtSpaces() bool {
+	// Repeated values stop where no separator follows them, so may be spaced out, as in 'a, b, c'.
+	if v.repeat {
+		return false
+	}
+
+	// Colors may contain spaces, as in 'rgb(0, 0, 0)', and enum values may too,
+	// but both consume only as much as is valid.
+	if v.value == verbColor || v.value == verbEnum {
+		return false
+	}
+
+	// Time layouts may contain spaces, as in '2006-01-02 15:04:05'.
+	if v.value == verbTime {
+		return !strings.Contains(v.timeLayout(), " ")
+	}
+
 	if v.value == verbString {
 		for _, f := range v.flags {
 			if f != ' ' {
@@ -61,3 +165,62 @@ func (v verb) stopAtSpaces() bool {
 
 	return true
 }
+
+// Interprets the options of a long-form verb according to its type.
+func (v *verb) parseOptions() error {
+	if v.value == verbEnum {
+		if len(v.enumValues()) == 0 {
+			return errors.New("requires one or more '|'-separated values")
+		}
+
+		return nil
+	}
+
+	if v.value == verbTime {
+		// A layout unchanged by formatting has no elements of the reference time, e.g. 'YYYY-MM-DD'.
+		if layout := v.timeLayout(); time.Unix(0, 0).UTC().Format(layout) == layout {
+			return fmt.Errorf("has layout '%s' without elements of the reference time", layout)
+		}
+
+		return nil
+	}
+
+	if v.options == "" {
+		return nil
+	}
+
+	var err error
+	v.constraints, err = parseConstraints(v.options)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range v.constraints {
+		if !c.length && v.value != verbInt && v.value != verbDecimal {
+			return fmt.Errorf("supports no value range constraint such as '%s'", c)
+		}
+	}
+
+	return nil
+}
+
+// Returns the values accepted by an enum verb, e.g. 'GET' and 'POST' for '%{enum:GET|POST}'.
+func (v verb) enumValues() []string {
+	var values []string
+	for _, value := range splitUnescaped(v.options, '|') {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// Returns the layout of a time verb, e.g. '2006-01-02' for '%{time:2006-01-02}', or RFC 3339 if none.
+func (v verb) timeLayout() string {
+	if v.options == "" {
+		return time.RFC3339
+	}
+
+	return unescapeLongForm(v.options)
+}
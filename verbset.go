@@ -0,0 +1,143 @@
+package unfmt
+
+import (
+	"fmt"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+CustomVerb defines a verb beyond those built in, for use in the formats of Scanners
+configured with it via WithVerb or a VerbSet.
+*/
+type CustomVerb struct {
+	// Match reports how many bytes at the start of 'str' the verb consumes,
+	// or an error if 'str' doesn't begin with a value for the verb. As for
+	// the built-in verbs other than '% s', 'str' ends at the next space.
+	Match func(str string) (int, error)
+
+	// Convert assigns the value of 'text', as matched, to 'target'. It also
+	// runs on new values of the target's type to check candidate matches of
+	// a format against a string, including those then rejected, so it must
+	// have no side effects beyond setting 'target'.
+	Convert func(text string, target interface{}) error
+
+	// New optionally returns a pointer to a new value of the type the verb
+	// most naturally assigns to, for ScanMap and ScanValues. Without it,
+	// those return an error for a format with the verb, and a default value
+	// or string value to render for the verb is checked with Match alone.
+	New func() interface{}
+
+	// Render optionally prints a value the verb can assign, for Scanner.Render
+	// and Marshal. Without it, values are printed as by fmt.Sprint.
+	Render func(value interface{}) (string, error)
+}
+
+func (cv CustomVerb) renderFunc() renderFunc {
+	return func(value interface{}, _ verb) (string, error) {
+		if cv.Render == nil {
+			return fmt.Sprint(value), nil
+		}
+
+		return cv.Render(value)
+	}
+}
+
+func (cv CustomVerb) assignFunc() assignFunc {
+	return func(str string, target interface{}, v verb) (int, error) {
+		n, err := cv.Match(str)
+		if err != nil {
+			return 0, err
+		}
+
+		if n < 0 || n > len(str) {
+			return 0, fmt.Errorf("custom verb '%s' matched %d bytes of '%s'", v, n, str)
+		}
+
+		// A nil target means the match is only being checked.
+		if target == nil {
+			return n, nil
+		}
+
+		err = cv.Convert(str[:n], target)
+		if err != nil {
+			return 0, err
+		}
+
+		return n, nil
+	}
+}
+
+/*
+VerbSet is a collection of custom verbs, registered by name, which Scanners can be
+configured with via WithVerbs. Registrations in one VerbSet don't affect others or
+Scanners not configured with it. A VerbSet is safe for concurrent use.
+*/
+type VerbSet struct {
+	mu    sync.RWMutex
+	verbs map[string]CustomVerb
+}
+
+// NewVerbSet initializes an empty VerbSet.
+func NewVerbSet() *VerbSet {
+	return &VerbSet{verbs: make(map[string]CustomVerb)}
+}
+
+/*
+Register adds a custom verb to the VerbSet under 'name'. A single-rune name, e.g. 'k',
+makes the verb available in both classic and long form, '%k' and '%{k}'; a longer one,
+e.g. 'hostname', in long form only, '%{hostname}'. Names must consist of letters and
+digits, and must not shadow a built-in verb or one already in the VerbSet.
+*/
+func (vs *VerbSet) Register(name string, cv CustomVerb) error {
+	err := checkCustomVerb(name, cv)
+	if err != nil {
+		return err
+	}
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	if _, ok := vs.verbs[name]; ok {
+		return fmt.Errorf("%w: verb '%s' already registered", ErrBadArg, name)
+	}
+
+	vs.verbs[name] = cv
+	return nil
+}
+
+func (vs *VerbSet) each(f func(name string, cv CustomVerb)) {
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+
+	for name, cv := range vs.verbs {
+		f(name, cv)
+	}
+}
+
+func checkCustomVerb(name string, cv CustomVerb) error {
+	if name == "" {
+		return fmt.Errorf("%w: verb name must not be empty", ErrBadArg)
+	}
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return fmt.Errorf("%w: verb name '%s' must consist of letters and digits", ErrBadArg, name)
+		}
+	}
+
+	if r, _ := utf8.DecodeRuneInString(name); utf8.RuneCountInString(name) == 1 && flagRunes.includes(r) {
+		return fmt.Errorf("%w: verb name '%s' is a flag", ErrBadArg, name)
+	}
+
+	if _, ok := assignFuncs[name]; ok {
+		return fmt.Errorf("%w: verb name '%s' shadows a built-in verb", ErrBadArg, name)
+	}
+
+	if cv.Match == nil || cv.Convert == nil {
+		return fmt.Errorf("%w: verb '%s' requires Match and Convert", ErrBadArg, name)
+	}
+
+	return nil
+}